@@ -0,0 +1,46 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"kanopt/internal/allocator/policy"
+)
+
+// policyCacheTTL bounds how long a compiled per-board policy is reused
+// before policyFor re-fetches and recompiles it, so a PUT to
+// /api/agent/policies/:boardId takes effect within a bounded time without
+// every alert re-fetching the document.
+const policyCacheTTL = 2 * time.Minute
+
+type policyCacheEntry struct {
+	evaluator *policy.Evaluator
+	expiresAt time.Time
+}
+
+// policyCache is a small per-board cache of compiled policy.Evaluators.
+type policyCache struct {
+	mu      sync.Mutex
+	entries map[string]policyCacheEntry
+}
+
+func newPolicyCache() *policyCache {
+	return &policyCache{entries: make(map[string]policyCacheEntry)}
+}
+
+func (c *policyCache) get(boardID string) (*policy.Evaluator, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[boardID]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.evaluator, true
+}
+
+func (c *policyCache) set(boardID string, evaluator *policy.Evaluator) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[boardID] = policyCacheEntry{evaluator: evaluator, expiresAt: time.Now().Add(policyCacheTTL)}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"kanopt/internal/allocator/solver"
+	"kanopt/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// defaultWIPCap is how many active tasks a candidate can hold before
+// solveBoardAssignment stops offering them new ones, for boards/users with
+// no more specific capacity signal available.
+const defaultWIPCap = 8
+
+// userStats summarizes one candidate's current load and history on a
+// board, computed directly from its tasks rather than round-tripping
+// through the API (the allocator already holds its own DB connection for
+// exactly this kind of read).
+type userStats struct {
+	activeTasks   int
+	storyPoints   int
+	cycleTimeDays []float64
+	completedTags map[string]int
+}
+
+func (s *userStats) avgCycleTimeDays() float64 {
+	if len(s.cycleTimeDays) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, d := range s.cycleTimeDays {
+		total += d
+	}
+	return total / float64(len(s.cycleTimeDays))
+}
+
+// skillMatch is the fraction of task's tags this user has touched before
+// (via a completed task carrying the same tag), 0 when the task has no
+// tags to match against.
+func (s *userStats) skillMatch(tags []string) float64 {
+	if len(tags) == 0 {
+		return 0
+	}
+	matched := 0
+	for _, t := range tags {
+		if s.completedTags[t] > 0 {
+			matched++
+		}
+	}
+	return float64(matched) / float64(len(tags))
+}
+
+// buildAllocationProblem loads every task currently on boardID along with
+// each candidate assignee's current load and history, and turns that into
+// a solver.Problem: one row per task, one column-worth of cost per
+// candidate who isn't already at their WIP cap.
+func (a *AllocatorAgent) buildAllocationProblem(boardID string) (solver.Problem, error) {
+	id, err := uuid.Parse(boardID)
+	if err != nil {
+		return solver.Problem{}, fmt.Errorf("invalid board ID: %w", err)
+	}
+
+	var tasks []models.Task
+	if err := a.db.Where("board_id = ? AND completed_at IS NULL", id).Find(&tasks).Error; err != nil {
+		return solver.Problem{}, fmt.Errorf("failed to load board tasks: %w", err)
+	}
+
+	var completed []models.Task
+	if err := a.db.Where("board_id = ? AND completed_at IS NOT NULL", id).Find(&completed).Error; err != nil {
+		return solver.Problem{}, fmt.Errorf("failed to load completed tasks: %w", err)
+	}
+
+	stats := make(map[string]*userStats)
+	statsFor := func(userID string) *userStats {
+		s, ok := stats[userID]
+		if !ok {
+			s = &userStats{completedTags: make(map[string]int)}
+			stats[userID] = s
+		}
+		return s
+	}
+
+	for _, t := range tasks {
+		if t.AssigneeID == nil {
+			continue
+		}
+		s := statsFor(t.AssigneeID.String())
+		s.activeTasks++
+		s.storyPoints += t.StoryPoints
+	}
+	for _, t := range completed {
+		if t.AssigneeID == nil || t.CompletedAt == nil {
+			continue
+		}
+		s := statsFor(t.AssigneeID.String())
+		s.cycleTimeDays = append(s.cycleTimeDays, t.CompletedAt.Sub(t.CreatedAt).Hours()/24)
+		for _, tag := range t.Tags {
+			s.completedTags[tag]++
+		}
+	}
+
+	if len(stats) == 0 {
+		// No history to build candidates from; nothing to solve.
+		return solver.Problem{BoardID: boardID}, nil
+	}
+
+	now := time.Now()
+	taskInputs := make([]solver.TaskInput, 0, len(tasks))
+	for _, t := range tasks {
+		currentAssignee := ""
+		if t.AssigneeID != nil {
+			currentAssignee = t.AssigneeID.String()
+		}
+
+		slack := 7 * 24.0 // a week of slack for tasks with no due date
+		if t.DueDate != nil {
+			slack = t.DueDate.Sub(now).Hours()
+		}
+
+		candidates := make([]solver.Candidate, 0, len(stats))
+		for userID, s := range stats {
+			slots := defaultWIPCap - s.activeTasks
+			if slots <= 0 {
+				continue
+			}
+			features := solver.Features{
+				CurrentWIP:         s.activeTasks,
+				WIPCap:             defaultWIPCap,
+				StoryPointLoad:     s.storyPoints,
+				StoryPointCapacity: defaultWIPCap * 3, // rough story-points-per-slot estimate
+				AvgCycleTimeDays:   s.avgCycleTimeDays(),
+				SkillMatch:         s.skillMatch(t.Tags),
+				DeadlineSlackHours: slack,
+			}
+			candidates = append(candidates, solver.Candidate{
+				AssigneeID: userID,
+				Slots:      slots,
+				Cost:       solver.Cost(features, solver.DefaultWeights),
+			})
+		}
+
+		if len(candidates) == 0 {
+			continue
+		}
+
+		taskInputs = append(taskInputs, solver.TaskInput{
+			TaskID:            t.ID.String(),
+			CurrentAssigneeID: currentAssignee,
+			Candidates:        candidates,
+		})
+	}
+
+	return solver.Problem{
+		BoardID:         boardID,
+		Tasks:           taskInputs,
+		NoAssignPenalty: 5,
+	}, nil
+}
+
+// solveBoardAssignment runs the solver for boardID and queues a
+// reassign_task PendingAction for every task whose optimal assignee
+// differs from who holds it today, replacing the old greedy
+// one-overloaded-user-at-a-time pairing with a single global optimum.
+func (a *AllocatorAgent) solveBoardAssignment(boardID string) error {
+	problem, err := a.buildAllocationProblem(boardID)
+	if err != nil {
+		return err
+	}
+	if len(problem.Tasks) == 0 {
+		return nil
+	}
+	if err := solver.Validate(problem); err != nil {
+		return err
+	}
+
+	assignment, diag, err := solver.Solve(context.Background(), problem)
+	if err != nil {
+		return fmt.Errorf("allocation solve failed: %w", err)
+	}
+
+	a.logger.WithFields(map[string]interface{}{
+		"board_id":   boardID,
+		"total_cost": diag.TotalCost,
+		"unassigned": len(diag.Unassigned),
+		"reassigned": len(assignment.TaskAssignee),
+	}).Info("Solved board task assignment")
+
+	for taskID, assigneeID := range assignment.TaskAssignee {
+		action := PendingAction{
+			ID:        uuid.New().String(),
+			Type:      "reassign_task",
+			BoardID:   boardID,
+			Priority:  2,
+			CreatedAt: time.Now(),
+			Data: map[string]interface{}{
+				"taskId":   taskID,
+				"toUserId": assigneeID,
+				"reason":   "solver_rebalance",
+			},
+		}
+		if err := a.queueAction(action); err != nil {
+			a.logger.WithError(err).WithField("task_id", taskID).Error("Failed to queue solver reassignment")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package ai
+
+import (
+	"sync"
+	"time"
+)
+
+type cacheEntry struct {
+	result    *PredictionResult
+	expiresAt time.Time
+}
+
+// PredictionCache holds recent predictions keyed by (boardID, timeHorizon,
+// dataVersion) so repeated requests for the same board/horizon/data don't
+// re-call the model within the TTL window.
+type PredictionCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[PredictionCacheKey]cacheEntry
+}
+
+func NewPredictionCache(ttl time.Duration) *PredictionCache {
+	return &PredictionCache{
+		ttl:     ttl,
+		entries: make(map[PredictionCacheKey]cacheEntry),
+	}
+}
+
+// Get returns the cached result for key along with how much of its TTL is
+// left, for callers that want to report cache freshness (e.g. querystats).
+func (pc *PredictionCache) Get(key PredictionCacheKey) (result *PredictionResult, ttlRemaining time.Duration, ok bool) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	entry, found := pc.entries[key]
+	if !found {
+		return nil, 0, false
+	}
+	remaining := time.Until(entry.expiresAt)
+	if remaining <= 0 {
+		return nil, 0, false
+	}
+	return entry.result, remaining, true
+}
+
+func (pc *PredictionCache) Set(key PredictionCacheKey, result *PredictionResult) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	pc.entries[key] = cacheEntry{result: result, expiresAt: time.Now().Add(pc.ttl)}
+}
@@ -0,0 +1,82 @@
+package ai
+
+import (
+	"sync"
+	"time"
+)
+
+// Breaker states, gobreaker-style: closed lets calls through, open
+// short-circuits them, half-open lets a single probe through to decide
+// whether to close again.
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half-open"
+)
+
+// circuitBreaker trips open after `threshold` consecutive failures and
+// stays open for `cooldown` before allowing a half-open probe.
+type circuitBreaker struct {
+	mu               sync.Mutex
+	threshold        int
+	cooldown         time.Duration
+	state            string
+	consecutiveFails int
+	openedAt         time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	if threshold <= 0 {
+		threshold = 5
+	}
+	if cooldown <= 0 {
+		cooldown = 30 * time.Second
+	}
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, state: StateClosed}
+}
+
+// Allow reports whether a call should be attempted, flipping an
+// open breaker to half-open once the cooldown has elapsed.
+func (cb *circuitBreaker) Allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case StateClosed:
+		return true
+	case StateHalfOpen:
+		// A probe is already in flight; hold everything else back until
+		// it records its outcome.
+		return false
+	}
+	if time.Since(cb.openedAt) < cb.cooldown {
+		return false
+	}
+	cb.state = StateHalfOpen
+	return true
+}
+
+func (cb *circuitBreaker) RecordSuccess() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.consecutiveFails = 0
+	cb.state = StateClosed
+}
+
+func (cb *circuitBreaker) RecordFailure() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.consecutiveFails++
+	if cb.state == StateHalfOpen || cb.consecutiveFails >= cb.threshold {
+		cb.state = StateOpen
+		cb.openedAt = time.Now()
+	}
+}
+
+func (cb *circuitBreaker) State() string {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state
+}
@@ -0,0 +1,174 @@
+// Package ai is a resilient client for the external AI prediction service:
+// configurable timeout, retry with exponential backoff and jitter, and a
+// circuit breaker that short-circuits to the caller's own fallback once the
+// service has failed too many times in a row, instead of every request
+// hanging on a dead service.
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ErrBreakerOpen is returned instead of calling out to the AI service while
+// the circuit breaker is open.
+var ErrBreakerOpen = errors.New("ai: circuit breaker open")
+
+type Config struct {
+	BaseURL          string
+	Timeout          time.Duration
+	MaxRetries       int
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	CacheTTL         time.Duration
+}
+
+type Client struct {
+	cfg        Config
+	baseURL    atomic.Value // string
+	httpClient *http.Client
+	breaker    *circuitBreaker
+	cache      *PredictionCache
+	metrics    *Metrics
+}
+
+func NewClient(cfg Config) *Client {
+	c := &Client{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+		breaker:    newCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+		cache:      NewPredictionCache(cfg.CacheTTL),
+		metrics:    NewMetrics(),
+	}
+	c.baseURL.Store(cfg.BaseURL)
+	return c
+}
+
+// SetBaseURL repoints the client at a new AI service URL, e.g. when
+// config.Watch delivers a reloaded config.AI.ServiceURL. Safe to call
+// concurrently with in-flight requests.
+func (c *Client) SetBaseURL(url string) {
+	c.baseURL.Store(url)
+}
+
+// Predict calls /api/predict, serving a cached result if one is fresh for
+// this (boardID, timeHorizon, dataVersion). The returned CacheInfo reports
+// whether the cache was used, for callers that surface it (e.g. querystats).
+func (c *Client) Predict(ctx context.Context, req PredictionRequest) (*PredictionResult, CacheInfo, error) {
+	key := PredictionCacheKey{BoardID: req.BoardID, TimeHorizon: req.TimeHorizon, DataVersion: req.DataVersion}
+	if cached, ttlRemaining, ok := c.cache.Get(key); ok {
+		return cached, CacheInfo{Hit: true, TTLRemaining: ttlRemaining}, nil
+	}
+
+	var result PredictionResult
+	if err := c.do(ctx, "/api/predict", req, &result); err != nil {
+		return nil, CacheInfo{}, err
+	}
+
+	c.cache.Set(key, &result)
+	return &result, CacheInfo{Hit: false, TTLRemaining: c.cfg.CacheTTL}, nil
+}
+
+// AnalyzeRisk calls /api/analyze-risk.
+func (c *Client) AnalyzeRisk(ctx context.Context, req RiskAnalysisRequest) (*RiskAnalysisResult, error) {
+	var result RiskAnalysisResult
+	if err := c.do(ctx, "/api/analyze-risk", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// BreakerState reports the circuit breaker's current state, for /ai/health.
+func (c *Client) BreakerState() string {
+	return c.breaker.State()
+}
+
+// Metrics reports call counts, latency percentiles, and breaker state, for
+// /ai/metrics.
+func (c *Client) Metrics() MetricsSnapshot {
+	return c.metrics.Snapshot(c.breaker.State())
+}
+
+func (c *Client) do(ctx context.Context, endpoint string, body, out interface{}) error {
+	if !c.breaker.Allow() {
+		return ErrBreakerOpen
+	}
+
+	start := time.Now()
+	err := c.requestWithRetry(ctx, endpoint, body, out)
+	c.metrics.Record(time.Since(start), err == nil)
+
+	if err != nil {
+		c.breaker.RecordFailure()
+		return err
+	}
+	c.breaker.RecordSuccess()
+	return nil
+}
+
+func (c *Client) requestWithRetry(ctx context.Context, endpoint string, body, out interface{}) error {
+	var lastErr error
+	for attempt := 0; attempt <= c.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if err := sleepBackoff(ctx, attempt); err != nil {
+				return err
+			}
+		}
+
+		if lastErr = c.request(ctx, endpoint, body, out); lastErr == nil {
+			return nil
+		}
+	}
+	return lastErr
+}
+
+// sleepBackoff waits an exponentially increasing delay (100ms, 200ms,
+// 400ms, ...) plus up to 50% jitter, so retries from many requests don't
+// all land on the AI service at once.
+func sleepBackoff(ctx context.Context, attempt int) error {
+	base := time.Duration(math.Pow(2, float64(attempt-1))) * 100 * time.Millisecond
+	jitter := time.Duration(rand.Int63n(int64(base)/2 + 1))
+
+	timer := time.NewTimer(base + jitter)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *Client) request(ctx context.Context, endpoint string, body, out interface{}) error {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL.Load().(string)+endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ai service returned status %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,76 @@
+package ai
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxLatencySamples bounds the rolling window used for percentiles so
+// Metrics never grows unbounded on a long-running process.
+const maxLatencySamples = 256
+
+// Metrics tracks call counts and a rolling latency window so /ai/metrics
+// can report p50/p95 without a full metrics library.
+type Metrics struct {
+	mu        sync.Mutex
+	calls     int64
+	failures  int64
+	latencies []time.Duration
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{}
+}
+
+func (m *Metrics) Record(latency time.Duration, success bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls++
+	if !success {
+		m.failures++
+	}
+
+	m.latencies = append(m.latencies, latency)
+	if len(m.latencies) > maxLatencySamples {
+		m.latencies = m.latencies[len(m.latencies)-maxLatencySamples:]
+	}
+}
+
+type MetricsSnapshot struct {
+	Calls        int64  `json:"calls"`
+	Failures     int64  `json:"failures"`
+	P50LatencyMs int64  `json:"p50LatencyMs"`
+	P95LatencyMs int64  `json:"p95LatencyMs"`
+	BreakerState string `json:"breakerState"`
+}
+
+func (m *Metrics) Snapshot(breakerState string) MetricsSnapshot {
+	m.mu.Lock()
+	samples := make([]time.Duration, len(m.latencies))
+	copy(samples, m.latencies)
+	calls, failures := m.calls, m.failures
+	m.mu.Unlock()
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+
+	return MetricsSnapshot{
+		Calls:        calls,
+		Failures:     failures,
+		P50LatencyMs: percentile(samples, 0.50).Milliseconds(),
+		P95LatencyMs: percentile(samples, 0.95).Milliseconds(),
+		BreakerState: breakerState,
+	}
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(p * float64(len(sorted)))
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
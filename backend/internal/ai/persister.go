@@ -0,0 +1,74 @@
+package ai
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"kanopt/internal/models"
+)
+
+// RiskPersister batches risk predictions into a single transactional
+// insert per interval instead of one db.Create per risk on the request
+// path, the same ticker-based worker shape as sprint.RunDailyWorker.
+type RiskPersister struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+	queue  chan models.RiskPrediction
+}
+
+func NewRiskPersister(db *gorm.DB, logger *logrus.Logger) *RiskPersister {
+	return &RiskPersister{
+		db:     db,
+		logger: logger,
+		queue:  make(chan models.RiskPrediction, 256),
+	}
+}
+
+// Enqueue schedules a risk prediction for the next batch flush. Its ID is
+// expected to already be set (so callers can respond to the client before
+// the row actually lands). Best-effort: a full queue drops the prediction
+// rather than blocking the request, matching how this codebase treats
+// non-critical writes elsewhere (e.g. RabbitMQ publish failures).
+func (p *RiskPersister) Enqueue(risk models.RiskPrediction) {
+	select {
+	case p.queue <- risk:
+	default:
+		p.logger.WithField("risk_id", risk.ID).Warn("RiskPersister: queue full, dropping risk prediction")
+	}
+}
+
+// Run flushes queued risk predictions in a single transaction every
+// interval, plus a final flush when ctx is cancelled.
+func (p *RiskPersister) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var batch []models.RiskPrediction
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := p.db.Transaction(func(tx *gorm.DB) error {
+			return tx.Create(&batch).Error
+		}); err != nil {
+			p.logger.WithError(err).Error("RiskPersister: failed to persist batch")
+		}
+		batch = nil
+	}
+
+	for {
+		select {
+		case risk := <-p.queue:
+			batch = append(batch, risk)
+		case <-ticker.C:
+			flush()
+		case <-ctx.Done():
+			flush()
+			return
+		}
+	}
+}
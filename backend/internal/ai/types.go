@@ -0,0 +1,100 @@
+package ai
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"kanopt/internal/models"
+)
+
+// PredictionRequest is the payload sent to the AI service's /api/predict.
+type PredictionRequest struct {
+	BoardID         uuid.UUID               `json:"boardId"`
+	TimeHorizon     string                  `json:"timeHorizon"`
+	Metrics         []string                `json:"metrics"`
+	VelocityHistory []models.VelocityMetric `json:"velocityHistory"`
+	CurrentTasks    []models.Task           `json:"currentTasks"`
+	DataVersion     string                  `json:"dataVersion"`
+}
+
+// MinMax is a predicted value's expected range.
+type MinMax struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+type VelocityPrediction struct {
+	Predicted float64 `json:"predicted"`
+	Range     MinMax  `json:"range"`
+}
+
+type CompletionPrediction struct {
+	ExpectedTasks  int     `json:"expectedTasks"`
+	TotalTasks     int     `json:"totalTasks"`
+	StoryPoints    int     `json:"storyPoints"`
+	CompletionRate float64 `json:"completionRate"`
+	DaysInHorizon  int     `json:"daysInHorizon"`
+}
+
+type RiskSummaryPrediction struct {
+	OverallRisk string   `json:"overallRisk"`
+	RiskFactors []string `json:"riskFactors"`
+	RiskScore   float64  `json:"riskScore"`
+}
+
+// PredictionResult is the typed shape of what /api/predict (or the local
+// fallback) returns, replacing the old map[string]interface{} that let a
+// malformed fallback slip past the compiler.
+type PredictionResult struct {
+	Velocity   *VelocityPrediction    `json:"velocity,omitempty"`
+	Completion *CompletionPrediction  `json:"completion,omitempty"`
+	Risk       *RiskSummaryPrediction `json:"risk,omitempty"`
+}
+
+// RiskAnalysisRequest is the payload sent to the AI service's
+// /api/analyze-risk.
+type RiskAnalysisRequest struct {
+	BoardID uuid.UUID     `json:"boardId"`
+	Tasks   []models.Task `json:"tasks"`
+	Factors []string      `json:"factors"`
+}
+
+type RiskItem struct {
+	TaskID      *uuid.UUID `json:"taskId,omitempty"`
+	Type        string     `json:"type"`
+	Level       string     `json:"level"`
+	Score       float64    `json:"score"`
+	Description string     `json:"description"`
+	Factors     []string   `json:"factors,omitempty"`
+}
+
+type RiskSummary struct {
+	TotalTasks    int     `json:"totalTasks"`
+	RiskyTasks    int     `json:"riskyTasks"`
+	AverageRisk   float64 `json:"averageRisk"`
+	HighRiskCount int     `json:"highRiskCount"`
+}
+
+// RiskAnalysisResult is the typed shape of /api/analyze-risk's response.
+type RiskAnalysisResult struct {
+	Risks   []RiskItem  `json:"risks"`
+	Summary RiskSummary `json:"summary"`
+}
+
+// PredictionCacheKey identifies a cached prediction. DataVersion lets the
+// caller invalidate the cache when the underlying board data changes
+// (e.g. a hash of the task/velocity rows) without waiting out the TTL.
+type PredictionCacheKey struct {
+	BoardID     uuid.UUID
+	TimeHorizon string
+	DataVersion string
+}
+
+// CacheInfo reports whether a Predict call was served from the
+// PredictionCache, for callers that surface cache freshness (e.g.
+// querystats on /ai/board/:id/predictions).
+type CacheInfo struct {
+	Hit          bool
+	TTLRemaining time.Duration
+}
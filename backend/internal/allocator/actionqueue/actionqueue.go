@@ -0,0 +1,473 @@
+// Package actionqueue is the allocator's durable work queue for
+// PendingActions. It replaces an in-memory map guarded by a mutex (lost on
+// crash, retried at most 3 times with no visibility) with per-priority
+// RabbitMQ queues plus a dead-letter exchange, mirroring the retry/DLQ
+// design internal/messaging already uses for board events.
+package actionqueue
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/sirupsen/logrus"
+)
+
+// Action is the durable, wire-level form of an allocator PendingAction.
+type Action struct {
+	ID        string                 `json:"id"`
+	Type      string                 `json:"type"`
+	BoardID   string                 `json:"boardId"`
+	Priority  int                    `json:"priority"`
+	Data      map[string]interface{} `json:"data"`
+	CreatedAt time.Time              `json:"createdAt"`
+}
+
+const (
+	ActionExchange = "kanopt.actions"
+	RetryExchange  = "kanopt.actions.retry"
+	DeadExchange   = "kanopt.actions.dlx"
+	DeadQueue      = "actions.dead"
+	DeadRoutingKey = "actions.dead"
+
+	// minPriority/maxPriority bound the priority queues that exist
+	// (actions.p1 .. actions.p3); Publish clamps anything outside this
+	// range to defaultPriority.
+	minPriority     = 1
+	maxPriority     = 3
+	defaultPriority = 2
+
+	// retryAttemptHeader tracks how many times an action has already been
+	// retried; it's set on every message published to a retry tier queue
+	// and survives the TTL expiry that dead-letters it back onto its
+	// priority queue.
+	retryAttemptHeader = "x-retry-attempt"
+	deadReasonHeader   = "x-dead-reason"
+	deadFailedAtHeader = "x-dead-failed-at"
+)
+
+// retryTiers are the per-attempt TTL queues a failed action cycles
+// through before landing in the dead queue.
+var retryTiers = []struct {
+	name string
+	ttl  time.Duration
+}{
+	{"30s", 30 * time.Second},
+	{"2m", 2 * time.Minute},
+	{"10m", 10 * time.Minute},
+}
+
+func maxRetryAttempts() int {
+	return len(retryTiers)
+}
+
+// Queue is the allocator's connection to its action exchange, priority
+// queues, retry tiers, and dead-letter queue.
+type Queue struct {
+	channel *amqp091.Channel
+	logger  *logrus.Logger
+}
+
+// New declares the action exchange/queues on channel and returns a Queue
+// ready for Publish/Consume. The caller owns channel's lifecycle (the
+// allocator already opens one shared channel off its RabbitMQ connection).
+func New(channel *amqp091.Channel, logger *logrus.Logger) (*Queue, error) {
+	q := &Queue{channel: channel, logger: logger}
+	if err := q.setup(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func priorityQueueName(priority int) string {
+	return fmt.Sprintf("actions.p%d", priority)
+}
+
+func retryQueueName(priority int, tier string) string {
+	return fmt.Sprintf("actions.retry.p%d.%s", priority, tier)
+}
+
+func (q *Queue) setup() error {
+	if err := q.channel.ExchangeDeclare(ActionExchange, "direct", true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare action exchange: %w", err)
+	}
+
+	for priority := minPriority; priority <= maxPriority; priority++ {
+		name := priorityQueueName(priority)
+		if _, err := q.channel.QueueDeclare(name, true, false, false, false, nil); err != nil {
+			return fmt.Errorf("failed to declare action queue %s: %w", name, err)
+		}
+		if err := q.channel.QueueBind(name, name, ActionExchange, false, nil); err != nil {
+			return fmt.Errorf("failed to bind action queue %s: %w", name, err)
+		}
+	}
+
+	if err := q.channel.ExchangeDeclare(RetryExchange, "direct", true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare retry exchange: %w", err)
+	}
+
+	for priority := minPriority; priority <= maxPriority; priority++ {
+		for _, tier := range retryTiers {
+			name := retryQueueName(priority, tier.name)
+			_, err := q.channel.QueueDeclare(name, true, false, false, false, amqp091.Table{
+				"x-message-ttl":             int64(tier.ttl / time.Millisecond),
+				"x-dead-letter-exchange":    ActionExchange,
+				"x-dead-letter-routing-key": priorityQueueName(priority),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to declare retry queue %s: %w", name, err)
+			}
+			if err := q.channel.QueueBind(name, name, RetryExchange, false, nil); err != nil {
+				return fmt.Errorf("failed to bind retry queue %s: %w", name, err)
+			}
+		}
+	}
+
+	if err := q.channel.ExchangeDeclare(DeadExchange, "direct", true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare dead-letter exchange: %w", err)
+	}
+	if _, err := q.channel.QueueDeclare(DeadQueue, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare dead queue: %w", err)
+	}
+	if err := q.channel.QueueBind(DeadQueue, DeadRoutingKey, DeadExchange, false, nil); err != nil {
+		return fmt.Errorf("failed to bind dead queue: %w", err)
+	}
+
+	return nil
+}
+
+func clampPriority(priority int) int {
+	if priority < minPriority || priority > maxPriority {
+		return defaultPriority
+	}
+	return priority
+}
+
+// Publish durably enqueues action onto its priority queue (clamping an
+// out-of-range Priority to defaultPriority).
+func (q *Queue) Publish(action Action) error {
+	action.Priority = clampPriority(action.Priority)
+
+	body, err := json.Marshal(action)
+	if err != nil {
+		return fmt.Errorf("failed to marshal action: %w", err)
+	}
+
+	name := priorityQueueName(action.Priority)
+	err = q.channel.Publish(ActionExchange, name, false, false, amqp091.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp091.Persistent,
+		Timestamp:    time.Now(),
+		Headers:      amqp091.Table{retryAttemptHeader: int32(0)},
+		Body:         body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish action: %w", err)
+	}
+
+	q.logger.WithFields(logrus.Fields{
+		"action_id":   action.ID,
+		"action_type": action.Type,
+		"board_id":    action.BoardID,
+		"priority":    action.Priority,
+	}).Info("Action queued")
+
+	return nil
+}
+
+// Consume drains the priority queues once every pollInterval, highest
+// priority first, invoking handler for each action it finds. A handler
+// error schedules a retry (backing off through retryTiers) or, once
+// attempts are exhausted, routes the action to the dead queue.
+//
+// isLeader may be nil, in which case every tick drains; when set, a tick
+// is skipped entirely (no channel.Get at all) while isLeader returns
+// false, so a non-leader replica never removes an action from its
+// priority queue without actually executing it.
+func (q *Queue) Consume(stop <-chan struct{}, pollInterval time.Duration, isLeader func() bool, handler func(Action) error) {
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if isLeader != nil && !isLeader() {
+					continue
+				}
+				q.drainOnce(handler)
+			}
+		}
+	}()
+}
+
+// drainOnce empties every priority queue (p3 down to p1, so higher
+// priority actions are always handled ahead of lower priority ones) in a
+// single pass.
+func (q *Queue) drainOnce(handler func(Action) error) {
+	for priority := maxPriority; priority >= minPriority; priority-- {
+		name := priorityQueueName(priority)
+		for {
+			d, ok, err := q.channel.Get(name, false)
+			if err != nil {
+				q.logger.WithError(err).WithField("queue", name).Error("Failed to read action queue")
+				break
+			}
+			if !ok {
+				break
+			}
+			q.handleDelivery(d, handler)
+		}
+	}
+}
+
+func attemptFromHeaders(headers amqp091.Table) int {
+	switch v := headers[retryAttemptHeader].(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	default:
+		return 0
+	}
+}
+
+func (q *Queue) handleDelivery(d amqp091.Delivery, handler func(Action) error) {
+	var action Action
+	if err := json.Unmarshal(d.Body, &action); err != nil {
+		q.logger.WithError(err).Error("Dropping unparseable queued action")
+		d.Ack(false)
+		return
+	}
+
+	handlerErr := handler(action)
+	if handlerErr == nil {
+		d.Ack(false)
+		return
+	}
+
+	fields := logrus.Fields{"action_id": action.ID, "action_type": action.Type}
+	attempt := attemptFromHeaders(d.Headers)
+
+	if attempt < maxRetryAttempts() {
+		nextAttempt := attempt + 1
+		if err := q.publishToRetry(action, nextAttempt); err != nil {
+			q.logger.WithError(err).WithFields(fields).Error("Failed to publish action to retry queue; requeueing natively")
+			d.Nack(false, true)
+			return
+		}
+		q.logger.WithError(handlerErr).WithFields(fields).WithField("retry_attempt", nextAttempt).Warn("Action execution failed; scheduled for retry")
+		d.Ack(false)
+		return
+	}
+
+	if err := q.publishToDead(action, attempt, handlerErr); err != nil {
+		q.logger.WithError(err).WithFields(fields).Error("Failed to publish action to dead queue; requeueing natively")
+		d.Nack(false, true)
+		return
+	}
+	q.logger.WithError(handlerErr).WithFields(fields).WithField("attempts", attempt).Error("Action execution failed permanently; routed to dead queue")
+	d.Ack(false)
+}
+
+func (q *Queue) publishToRetry(action Action, attempt int) error {
+	body, err := json.Marshal(action)
+	if err != nil {
+		return fmt.Errorf("failed to marshal action: %w", err)
+	}
+
+	tier := retryTiers[attempt-1]
+	name := retryQueueName(clampPriority(action.Priority), tier.name)
+	return q.channel.Publish(RetryExchange, name, false, false, amqp091.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp091.Persistent,
+		Timestamp:    time.Now(),
+		Headers:      amqp091.Table{retryAttemptHeader: int32(attempt)},
+		Body:         body,
+	})
+}
+
+func (q *Queue) publishToDead(action Action, attempt int, cause error) error {
+	body, err := json.Marshal(action)
+	if err != nil {
+		return fmt.Errorf("failed to marshal action: %w", err)
+	}
+
+	return q.channel.Publish(DeadExchange, DeadRoutingKey, false, false, amqp091.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp091.Persistent,
+		Timestamp:    time.Now(),
+		Headers: amqp091.Table{
+			retryAttemptHeader: int32(attempt),
+			deadReasonHeader:   cause.Error(),
+			deadFailedAtHeader: time.Now().Format(time.RFC3339),
+		},
+		Body: body,
+	})
+}
+
+// Depths reports the current message count of every priority queue plus
+// the dead queue, for /metrics.
+func (q *Queue) Depths() (map[string]int, error) {
+	depths := make(map[string]int, maxPriority+1)
+	for priority := minPriority; priority <= maxPriority; priority++ {
+		name := priorityQueueName(priority)
+		info, err := q.channel.QueueInspect(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect %s: %w", name, err)
+		}
+		depths[name] = info.Messages
+	}
+	info, err := q.channel.QueueInspect(DeadQueue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect %s: %w", DeadQueue, err)
+	}
+	depths[DeadQueue] = info.Messages
+	return depths, nil
+}
+
+// DeadEntry is one message sitting in the dead queue, as returned by
+// ListDead.
+type DeadEntry struct {
+	Action   Action    `json:"action"`
+	Attempts int       `json:"attempts"`
+	Reason   string    `json:"reason"`
+	FailedAt time.Time `json:"failedAt"`
+}
+
+// ListDead returns up to max messages currently in the dead queue without
+// removing them: each is popped and immediately republished so the
+// queue's contents are left unchanged. Meant for admin inspection, not
+// high-frequency polling.
+func (q *Queue) ListDead(max int) ([]DeadEntry, error) {
+	// Bound the scan by the queue's current depth, so messages republished
+	// to the tail below aren't read (and listed) a second time.
+	info, err := q.channel.QueueInspect(DeadQueue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect dead queue: %w", err)
+	}
+	if info.Messages < max {
+		max = info.Messages
+	}
+
+	var entries []DeadEntry
+	for i := 0; i < max; i++ {
+		d, ok, err := q.channel.Get(DeadQueue, false)
+		if err != nil {
+			return entries, fmt.Errorf("failed to read dead queue: %w", err)
+		}
+		if !ok {
+			break
+		}
+
+		entry, parseErr := parseDeadDelivery(d)
+		if parseErr != nil {
+			q.logger.WithError(parseErr).Warn("Dropping unparseable dead-queue message")
+			d.Ack(false)
+			continue
+		}
+		entries = append(entries, entry)
+
+		if err := q.republishDead(d.Body, d.Headers); err != nil {
+			d.Ack(false)
+			return entries, fmt.Errorf("failed to restore dead-queue message after listing: %w", err)
+		}
+		d.Ack(false)
+	}
+	return entries, nil
+}
+
+// ReplayDead removes the dead-queue message for actionID and republishes
+// it onto its original priority queue for reprocessing.
+func (q *Queue) ReplayDead(actionID string) error {
+	return q.drainDeadUntil(actionID, func(action Action) error {
+		return q.Publish(action)
+	})
+}
+
+// DropDead permanently removes the dead-queue message for actionID.
+func (q *Queue) DropDead(actionID string) error {
+	return q.drainDeadUntil(actionID, func(Action) error { return nil })
+}
+
+// drainDeadUntil scans the dead queue for the message whose Action.ID is
+// actionID. Every other message it passes over is republished back onto
+// the dead queue unchanged; the matching message is removed and passed to
+// onMatch, whose return value decides whether it's gone for good (nil) or
+// restored (any other error, e.g. a replay publish failing).
+func (q *Queue) drainDeadUntil(actionID string, onMatch func(Action) error) error {
+	info, err := q.channel.QueueInspect(DeadQueue)
+	if err != nil {
+		return fmt.Errorf("failed to inspect dead queue: %w", err)
+	}
+
+	for i := 0; i < info.Messages; i++ {
+		d, ok, err := q.channel.Get(DeadQueue, false)
+		if err != nil {
+			return fmt.Errorf("failed to read dead queue: %w", err)
+		}
+		if !ok {
+			break
+		}
+
+		var action Action
+		if err := json.Unmarshal(d.Body, &action); err != nil {
+			q.logger.WithError(err).Warn("Dropping unparseable dead-queue message")
+			d.Ack(false)
+			continue
+		}
+
+		if action.ID != actionID {
+			if err := q.republishDead(d.Body, d.Headers); err != nil {
+				d.Ack(false)
+				return fmt.Errorf("failed to restore dead-queue message: %w", err)
+			}
+			d.Ack(false)
+			continue
+		}
+
+		if matchErr := onMatch(action); matchErr != nil {
+			if err := q.republishDead(d.Body, d.Headers); err != nil {
+				q.logger.WithError(err).Error("Failed to restore dead-queue message after failed replay")
+			}
+			d.Ack(false)
+			return matchErr
+		}
+		d.Ack(false)
+		return nil
+	}
+
+	return fmt.Errorf("no dead action found with id %s", actionID)
+}
+
+func (q *Queue) republishDead(body []byte, headers amqp091.Table) error {
+	return q.channel.Publish(DeadExchange, DeadRoutingKey, false, false, amqp091.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp091.Persistent,
+		Timestamp:    time.Now(),
+		Headers:      headers,
+		Body:         body,
+	})
+}
+
+func parseDeadDelivery(d amqp091.Delivery) (DeadEntry, error) {
+	var action Action
+	if err := json.Unmarshal(d.Body, &action); err != nil {
+		return DeadEntry{}, err
+	}
+
+	entry := DeadEntry{Action: action, Attempts: attemptFromHeaders(d.Headers)}
+	if reason, ok := d.Headers[deadReasonHeader].(string); ok {
+		entry.Reason = reason
+	}
+	if failedAt, ok := d.Headers[deadFailedAtHeader].(string); ok {
+		if t, err := time.Parse(time.RFC3339, failedAt); err == nil {
+			entry.FailedAt = t
+		}
+	}
+	return entry, nil
+}
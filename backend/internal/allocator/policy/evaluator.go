@@ -0,0 +1,193 @@
+package policy
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+)
+
+// Alert is the snapshot of the triggering RiskAlert exposed to a rule's
+// When expression as `alert`.
+type Alert struct {
+	Type  string  `expr:"type"`
+	Level string  `expr:"level"`
+	Score float64 `expr:"score"`
+}
+
+// Workload is the snapshot of current board workload exposed to a rule's
+// When expression as `workload`.
+type Workload struct {
+	OverloadedCount int `expr:"overloadedCount"`
+}
+
+// env is the expr evaluation environment; Alert/Workload field names
+// above must match the expr tags here for a rule to reference them.
+type env struct {
+	Alert    Alert    `expr:"alert"`
+	Workload Workload `expr:"workload"`
+}
+
+// Decision is the result of evaluating a policy for one proposed action.
+type Decision struct {
+	// Allow is false if no rule matched, the action wasn't permitted, a
+	// cooldown is still active, or quiet hours are in effect.
+	Allow bool
+	// Advisory is true when the policy's mode is ModeAdvisory: the
+	// caller should record a Suggestion instead of enqueuing a
+	// PendingAction.
+	Advisory bool
+	// Priority is the matched rule's priority, or the proposed priority
+	// the caller passed in if the rule didn't set one.
+	Priority int
+	// Reason explains why Allow is false, for logging.
+	Reason string
+}
+
+// compiledRule pairs a Rule with its compiled When expression.
+type compiledRule struct {
+	rule    Rule
+	program *vm.Program
+}
+
+// Evaluator is a Policy compiled for repeated evaluation, plus the
+// per-rule cooldown state that makes a rule fire at most once per
+// Rule.Cooldown.
+type Evaluator struct {
+	policy Policy
+	rules  []compiledRule
+
+	mu          sync.Mutex
+	lastFiredAt map[string]time.Time
+}
+
+// NewEvaluator compiles every rule's When expression. An error here means
+// the policy document is malformed in a way Validate should already have
+// caught; NewEvaluator re-checks it because the document may have been
+// parsed long before (e.g. loaded from the database) and expr.Compile is
+// the authoritative check for expression syntax.
+func NewEvaluator(p Policy) (*Evaluator, error) {
+	e := &Evaluator{policy: p, lastFiredAt: make(map[string]time.Time)}
+
+	for _, rule := range p.Rules {
+		program, err := expr.Compile(rule.When, expr.Env(env{}), expr.AsBool())
+		if err != nil {
+			return nil, fmt.Errorf("policy: rule %q has invalid when expression: %w", rule.Name, err)
+		}
+		e.rules = append(e.rules, compiledRule{rule: rule, program: program})
+	}
+
+	return e, nil
+}
+
+// Decide evaluates every rule targeting actionType in order, subject to
+// quiet hours and AllowedActionTypes. The first rule whose When matches
+// alert/workload decides: it allows the action if its cooldown has
+// elapsed and denies it otherwise; later rules are not tried. now is passed
+// in (rather than read from time.Now()) so quiet hours and cooldowns are
+// deterministic to test.
+func (e *Evaluator) Decide(now time.Time, alert Alert, workload Workload, actionType string, proposedPriority int) Decision {
+	if !e.actionAllowed(actionType) {
+		return Decision{Reason: fmt.Sprintf("action %q not in allowedActionTypes", actionType)}
+	}
+
+	if e.inQuietHours(now) {
+		return Decision{Reason: "quiet hours in effect"}
+	}
+
+	env := env{Alert: alert, Workload: workload}
+
+	for _, cr := range e.rules {
+		if cr.rule.Action != actionType {
+			continue
+		}
+
+		matched, err := expr.Run(cr.program, env)
+		if err != nil || matched != true {
+			continue
+		}
+
+		if !e.coolDownElapsed(cr.rule, now) {
+			return Decision{Reason: fmt.Sprintf("rule %q still in cooldown", cr.rule.Name)}
+		}
+		e.recordFired(cr.rule, now)
+
+		priority := cr.rule.Priority
+		if priority == 0 {
+			priority = proposedPriority
+		}
+
+		return Decision{
+			Allow:    true,
+			Advisory: e.policy.Mode == ModeAdvisory,
+			Priority: priority,
+		}
+	}
+
+	return Decision{Reason: fmt.Sprintf("no rule matched for action %q", actionType)}
+}
+
+func (e *Evaluator) actionAllowed(actionType string) bool {
+	for _, t := range e.policy.AllowedActionTypes {
+		if t == actionType {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Evaluator) inQuietHours(now time.Time) bool {
+	qh := e.policy.QuietHours
+	if qh == nil {
+		return false
+	}
+
+	loc := time.UTC
+	if qh.Timezone != "" {
+		if l, err := time.LoadLocation(qh.Timezone); err == nil {
+			loc = l
+		}
+	}
+
+	start, errStart := parseClock(qh.Start)
+	end, errEnd := parseClock(qh.End)
+	if errStart != nil || errEnd != nil {
+		return false
+	}
+
+	local := now.In(loc)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	elapsed := local.Sub(midnight)
+
+	if start <= end {
+		return elapsed >= start && elapsed < end
+	}
+	// Wraps past midnight, e.g. 22:00 -> 07:00.
+	return elapsed >= start || elapsed < end
+}
+
+func (e *Evaluator) coolDownElapsed(rule Rule, now time.Time) bool {
+	cooldown := rule.cooldown()
+	if cooldown <= 0 {
+		return true
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	last, ok := e.lastFiredAt[rule.Name]
+	if !ok {
+		return true
+	}
+	return now.Sub(last) >= cooldown
+}
+
+func (e *Evaluator) recordFired(rule Rule, now time.Time) {
+	if rule.cooldown() <= 0 {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lastFiredAt[rule.Name] = now
+}
@@ -0,0 +1,179 @@
+// Package policy lets each board configure allocator behavior without a
+// code change: what alert-driven actions are allowed, under what
+// conditions (via a small expression language), how often, and when
+// (quiet hours), plus whether the agent should act autonomously or only
+// suggest. handleBottleneckAlert and friends used to hard-code all of
+// this; they now build an Alert/Workload snapshot and ask an Evaluator.
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"gopkg.in/yaml.v3"
+)
+
+// Mode controls what a matched Rule produces: Autonomous enqueues a
+// PendingAction directly, Advisory only records a Suggestion for a human
+// to approve.
+type Mode string
+
+const (
+	ModeAutonomous Mode = "autonomous"
+	ModeAdvisory   Mode = "advisory"
+)
+
+// QuietHours blocks every rule from firing between Start and End
+// (HH:MM, 24-hour, in Timezone) regardless of whether its condition
+// matched. An empty QuietHours (the zero value) never applies.
+type QuietHours struct {
+	Start    string `json:"start" yaml:"start"`
+	End      string `json:"end" yaml:"end"`
+	Timezone string `json:"timezone" yaml:"timezone"`
+}
+
+// Rule maps a condition to an action: when When evaluates truthy against
+// an Alert/Workload snapshot, Action is allowed to fire for the alert
+// that triggered evaluation, at most once per Cooldown.
+type Rule struct {
+	Name     string `json:"name" yaml:"name"`
+	When     string `json:"when" yaml:"when"`
+	Action   string `json:"action" yaml:"action"`
+	Priority int    `json:"priority" yaml:"priority"`
+	// Cooldown is a Go duration string (e.g. "10m"); empty means no
+	// cooldown, the rule can fire every time it matches.
+	Cooldown string `json:"cooldown" yaml:"cooldown"`
+}
+
+// cooldown parses Cooldown, treating an empty or invalid value as no
+// cooldown at all.
+func (r Rule) cooldown() time.Duration {
+	if r.Cooldown == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(r.Cooldown)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// Policy is one board's agent_policies document: what it's allowed to
+// do, how, and when.
+type Policy struct {
+	Mode Mode `json:"mode" yaml:"mode"`
+	// AllowedActionTypes gates every rule: a rule whose Action isn't
+	// listed here never fires, no matter what When evaluates to.
+	AllowedActionTypes []string    `json:"allowedActionTypes" yaml:"allowedActionTypes"`
+	QuietHours         *QuietHours `json:"quietHours,omitempty" yaml:"quietHours,omitempty"`
+	Rules              []Rule      `json:"rules" yaml:"rules"`
+}
+
+// Parse decodes a policy document in either YAML or JSON. format is
+// matched case-insensitively; anything other than "json" is treated as
+// YAML, since YAML is a superset of JSON syntax and that's the format
+// teams are expected to author by hand.
+func Parse(data []byte, format string) (Policy, error) {
+	var p Policy
+	var err error
+	if strings.EqualFold(format, "json") {
+		err = json.Unmarshal(data, &p)
+	} else {
+		err = yaml.Unmarshal(data, &p)
+	}
+	if err != nil {
+		return Policy{}, fmt.Errorf("policy: failed to parse document: %w", err)
+	}
+	return p, nil
+}
+
+// Validate checks a parsed Policy for mistakes that would otherwise only
+// surface as a rule silently never firing: an unknown mode, a rule
+// targeting an action outside AllowedActionTypes, a malformed condition,
+// or an unparseable cooldown.
+func Validate(p Policy) error {
+	switch p.Mode {
+	case ModeAutonomous, ModeAdvisory:
+	case "":
+		return fmt.Errorf("policy: mode is required (%q or %q)", ModeAutonomous, ModeAdvisory)
+	default:
+		return fmt.Errorf("policy: unknown mode %q", p.Mode)
+	}
+
+	allowed := make(map[string]bool, len(p.AllowedActionTypes))
+	for _, t := range p.AllowedActionTypes {
+		allowed[t] = true
+	}
+
+	for _, rule := range p.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("policy: rule missing name")
+		}
+		if rule.Action == "" {
+			return fmt.Errorf("policy: rule %q missing action", rule.Name)
+		}
+		if !allowed[rule.Action] {
+			return fmt.Errorf("policy: rule %q targets action %q, which isn't in allowedActionTypes", rule.Name, rule.Action)
+		}
+		if rule.When == "" {
+			return fmt.Errorf("policy: rule %q missing when condition", rule.Name)
+		}
+		if _, err := expr.Compile(rule.When, expr.Env(env{}), expr.AsBool()); err != nil {
+			return fmt.Errorf("policy: rule %q has invalid when condition: %w", rule.Name, err)
+		}
+		if rule.Cooldown != "" {
+			if _, err := time.ParseDuration(rule.Cooldown); err != nil {
+				return fmt.Errorf("policy: rule %q has invalid cooldown %q: %w", rule.Name, rule.Cooldown, err)
+			}
+		}
+	}
+
+	if p.QuietHours != nil {
+		if _, err := parseClock(p.QuietHours.Start); err != nil {
+			return fmt.Errorf("policy: invalid quietHours.start: %w", err)
+		}
+		if _, err := parseClock(p.QuietHours.End); err != nil {
+			return fmt.Errorf("policy: invalid quietHours.end: %w", err)
+		}
+		if p.QuietHours.Timezone != "" {
+			if _, err := time.LoadLocation(p.QuietHours.Timezone); err != nil {
+				return fmt.Errorf("policy: invalid quietHours.timezone: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// DefaultPolicy reproduces the allocator's previous hard-coded behavior
+// for a board that hasn't configured one: autonomous, every existing
+// action type allowed, one always-on rule per alert type at the
+// priorities handle*Alert used to hard-code, no cooldown or quiet hours.
+func DefaultPolicy() Policy {
+	return Policy{
+		Mode: ModeAutonomous,
+		AllowedActionTypes: []string{
+			"adjust_wip_limits",
+			"redistribute_tasks",
+			"reassign_overdue",
+			"enforce_wip_limits",
+		},
+		Rules: []Rule{
+			{Name: "default_bottleneck", When: "alert.type == \"bottleneck\"", Action: "adjust_wip_limits", Priority: 2},
+			{Name: "default_overload", When: "alert.type == \"overload\"", Action: "redistribute_tasks", Priority: 1},
+			{Name: "default_deadline_risk", When: "alert.type == \"deadline_risk\"", Action: "reassign_overdue", Priority: 3},
+			{Name: "default_wip_violation", When: "alert.type == \"wip_violation\"", Action: "enforce_wip_limits", Priority: 2},
+		},
+	}
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("expected HH:MM, got %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
@@ -0,0 +1,93 @@
+package solver
+
+import "math"
+
+// hungarianMin finds the minimum-cost perfect assignment of every row to a
+// distinct column in cost, using the O(n^2*m) Kuhn-Munkres algorithm with
+// potentials. cost must have at least as many columns as rows (pad with
+// dummy columns first if not - see Solve). Returns, for each row, the
+// column it was assigned to.
+//
+// This is the classic shortest-augmenting-path formulation of the
+// Hungarian algorithm: it adds rows one at a time, growing an augmenting
+// path from the new row through already-assigned columns until it reaches
+// an unassigned one, re-pricing (u, v) along the way so every edge it
+// considers stays non-negative.
+func hungarianMin(cost [][]float64) []int {
+	n := len(cost)
+	if n == 0 {
+		return nil
+	}
+	m := len(cost[0])
+	if m < n {
+		panic("hungarianMin: cost must have at least as many columns as rows")
+	}
+
+	const inf = math.MaxFloat64
+
+	// u/v are the row/column potentials; p[j] is the row currently
+	// assigned to column j (0 meaning "none", since this is the classic
+	// 1-indexed formulation adapted to Go's 0-indexed slices by leaving
+	// index 0 unused).
+	u := make([]float64, n+1)
+	v := make([]float64, m+1)
+	p := make([]int, m+1)
+	way := make([]int, m+1)
+
+	for i := 1; i <= n; i++ {
+		p[0] = i
+		j0 := 0
+		minv := make([]float64, m+1)
+		used := make([]bool, m+1)
+		for j := range minv {
+			minv[j] = inf
+		}
+
+		for {
+			used[j0] = true
+			i0 := p[j0]
+			delta := inf
+			j1 := -1
+			for j := 1; j <= m; j++ {
+				if used[j] {
+					continue
+				}
+				cur := cost[i0-1][j-1] - u[i0] - v[j]
+				if cur < minv[j] {
+					minv[j] = cur
+					way[j] = j0
+				}
+				if minv[j] < delta {
+					delta = minv[j]
+					j1 = j
+				}
+			}
+			for j := 0; j <= m; j++ {
+				if used[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+			j0 = j1
+			if p[j0] == 0 {
+				break
+			}
+		}
+
+		for j0 != 0 {
+			j1 := way[j0]
+			p[j0] = p[j1]
+			j0 = j1
+		}
+	}
+
+	result := make([]int, n)
+	for j := 1; j <= m; j++ {
+		if p[j] != 0 {
+			result[p[j]-1] = j - 1
+		}
+	}
+	return result
+}
@@ -0,0 +1,264 @@
+// Package solver models assignee allocation for a board's tasks as a
+// weighted bipartite assignment problem and solves it globally (minimum
+// total cost across every task at once) instead of pairing off one
+// overloaded/underloaded user at a time.
+package solver
+
+import (
+	"context"
+	"fmt"
+)
+
+// Features are the per-(task, candidate) inputs Cost combines into a
+// single scalar. Cost rises with load and cycle time and falls as
+// SkillMatch and DeadlineSlackHours rise.
+type Features struct {
+	// CurrentWIP is how many active tasks the candidate already has.
+	CurrentWIP int
+	// WIPCap is the candidate's configured WIP limit; CurrentWIP >= WIPCap
+	// means the candidate has no free slots (see Candidate.Slots).
+	WIPCap int
+	// StoryPointLoad is the candidate's current in-progress story points.
+	StoryPointLoad int
+	// StoryPointCapacity is roughly how many story points the candidate
+	// can carry before being considered at capacity.
+	StoryPointCapacity int
+	// AvgCycleTimeDays is the candidate's historical average cycle time
+	// (days from start to completion) on tasks like this one; lower is
+	// better (faster).
+	AvgCycleTimeDays float64
+	// SkillMatch is 0..1, the fraction of the task's tags the candidate
+	// has handled before; 1 means a perfect match.
+	SkillMatch float64
+	// DeadlineSlackHours is the time between now and the task's due date.
+	// Negative means already overdue. A tight deadline raises the cost of
+	// assigning to a candidate with a slow average cycle time.
+	DeadlineSlackHours float64
+}
+
+// Weights controls how Cost blends Features into a scalar. The defaults
+// (DefaultWeights) were picked to keep any single dimension from dominating
+// while still clearly favoring skilled, lightly-loaded, fast candidates.
+type Weights struct {
+	WIPLoad      float64
+	StoryPoint   float64
+	CycleTime    float64
+	SkillMatch   float64
+	DeadlineRisk float64
+}
+
+// DefaultWeights is used when a Problem doesn't set its own.
+var DefaultWeights = Weights{
+	WIPLoad:      3.0,
+	StoryPoint:   2.0,
+	CycleTime:    1.0,
+	SkillMatch:   4.0,
+	DeadlineRisk: 2.5,
+}
+
+// Cost combines f into a single non-negative scalar; lower means a better
+// candidate for the task. It never returns a negative value, which the
+// Hungarian solver relies on when padding with dummy rows/columns.
+func Cost(f Features, w Weights) float64 {
+	wipRatio := 0.0
+	if f.WIPCap > 0 {
+		wipRatio = float64(f.CurrentWIP) / float64(f.WIPCap)
+	}
+
+	spRatio := 0.0
+	if f.StoryPointCapacity > 0 {
+		spRatio = float64(f.StoryPointLoad) / float64(f.StoryPointCapacity)
+	}
+
+	// deadlineRisk grows as slack shrinks (and is clamped at 0 once there's
+	// a day or more of headroom), scaled by how slow this candidate
+	// historically is: a fast candidate's cycle time matters less when a
+	// deadline is tight.
+	slackDays := f.DeadlineSlackHours / 24
+	urgency := 0.0
+	if slackDays < 1 {
+		urgency = 1 - slackDays
+		if urgency > 3 {
+			urgency = 3
+		}
+	}
+
+	return w.WIPLoad*wipRatio +
+		w.StoryPoint*spRatio +
+		w.CycleTime*(f.AvgCycleTimeDays/7) +
+		w.SkillMatch*(1-f.SkillMatch) +
+		w.DeadlineRisk*urgency*(f.AvgCycleTimeDays/7)
+}
+
+// Candidate is one assignee option for a task, with Cost already computed
+// from that pair's Features (via Cost) by the caller, since only the
+// caller has the domain context (board state, user history) to build
+// Features in the first place - this package is just the optimizer.
+type Candidate struct {
+	AssigneeID string
+	// Slots is how many additional tasks this candidate can still take on
+	// (e.g. WIPCap - CurrentWIP, floored at 0) before violating its WIP
+	// cap. Solve creates one matrix column per slot, so a candidate with
+	// 3 free slots can receive up to 3 of the tasks in this Problem.
+	Slots int
+	Cost  float64
+}
+
+// TaskInput is one task to be assigned, along with every candidate willing
+// (or eligible) to take it.
+type TaskInput struct {
+	TaskID string
+	// CurrentAssigneeID is who holds the task today, or "" if unassigned.
+	// Solve reports a task whose optimal assignee is unchanged as
+	// "unassigned" in Diagnostics rather than as a no-op delta.
+	CurrentAssigneeID string
+	Candidates        []Candidate
+}
+
+// Problem is one board's full assignment instance.
+type Problem struct {
+	BoardID string
+	Tasks   []TaskInput
+	// NoAssignPenalty is the cost of leaving a task on its current
+	// assignee rather than moving it to any candidate. Every task gets one
+	// implicit "stay put" column priced at this, which is what lets the
+	// solver leave tasks unassigned when every real candidate is at
+	// capacity or simply a worse fit than the status quo.
+	NoAssignPenalty float64
+}
+
+// Assignment is Solve's result: the optimal assignee for every task that
+// should move. Tasks whose optimal assignee equals CurrentAssigneeID, or
+// that were left on the "stay put" column, are omitted here and listed in
+// Diagnostics.Unassigned instead.
+type Assignment struct {
+	TaskAssignee map[string]string
+}
+
+// Diagnostics reports what Solve did, for logging and the admin API.
+type Diagnostics struct {
+	TotalCost  float64
+	Unassigned []string
+	// ColumnsConsidered is the padded matrix width (real candidate slots
+	// plus stay-put/dummy columns), useful for sanity-checking solve time
+	// against board size.
+	ColumnsConsidered int
+}
+
+// Solve computes the minimum-total-cost assignment of every task in p to
+// at most one candidate, respecting each candidate's Slots (so a
+// lightly-loaded user can still only absorb as many tasks as their
+// remaining WIP cap allows). Once padded it's a rectangular assignment
+// problem with at least as many columns as rows: each task is a row; each candidate contributes one column per
+// free slot; each task additionally gets its own "stay put" column priced
+// at NoAssignPenalty so the solver always has a feasible column for every
+// row, which is what lets over-capacity boards (more tasks than free
+// slots) leave some tasks on their current assignee instead of failing.
+func Solve(ctx context.Context, p Problem) (Assignment, Diagnostics, error) {
+	if err := ctx.Err(); err != nil {
+		return Assignment{}, Diagnostics{}, err
+	}
+	if len(p.Tasks) == 0 {
+		return Assignment{TaskAssignee: map[string]string{}}, Diagnostics{}, nil
+	}
+
+	penalty := p.NoAssignPenalty
+	if penalty <= 0 {
+		penalty = 1000
+	}
+
+	// One column per (candidate, free slot) pair, shared across every task
+	// that lists that candidate - the Hungarian algorithm picks which task
+	// (if any) actually uses each slot.
+	type slotKey struct {
+		assigneeID string
+		slot       int
+	}
+	costByTaskAndAssignee := make([]map[string]float64, len(p.Tasks))
+	slotsByAssignee := make(map[string]int)
+	for i, task := range p.Tasks {
+		costByTaskAndAssignee[i] = make(map[string]float64, len(task.Candidates))
+		for _, cand := range task.Candidates {
+			costByTaskAndAssignee[i][cand.AssigneeID] = cand.Cost
+			if cand.Slots > slotsByAssignee[cand.AssigneeID] {
+				slotsByAssignee[cand.AssigneeID] = cand.Slots
+			}
+		}
+	}
+
+	var slotKeys []slotKey
+	for assigneeID, slots := range slotsByAssignee {
+		for s := 0; s < slots; s++ {
+			slotKeys = append(slotKeys, slotKey{assigneeID, s})
+		}
+	}
+
+	numTasks := len(p.Tasks)
+	numCols := len(slotKeys) + numTasks // + one stay-put column per task
+	matrix := make([][]float64, numTasks)
+	const unreachable = 1e12
+	for i := range matrix {
+		row := make([]float64, numCols)
+		for c, sk := range slotKeys {
+			cost, ok := costByTaskAndAssignee[i][sk.assigneeID]
+			if !ok {
+				row[c] = unreachable
+				continue
+			}
+			row[c] = cost
+		}
+		for t := 0; t < numTasks; t++ {
+			if t == i {
+				row[len(slotKeys)+t] = penalty
+			} else {
+				row[len(slotKeys)+t] = unreachable
+			}
+		}
+		matrix[i] = row
+	}
+
+	assignedCol := hungarianMin(matrix)
+
+	result := Assignment{TaskAssignee: map[string]string{}}
+	diag := Diagnostics{ColumnsConsidered: numCols}
+
+	for i, col := range assignedCol {
+		task := p.Tasks[i]
+		diag.TotalCost += matrix[i][col]
+
+		if col >= len(slotKeys) {
+			// Stay-put column: no change.
+			diag.Unassigned = append(diag.Unassigned, task.TaskID)
+			continue
+		}
+
+		assigneeID := slotKeys[col].assigneeID
+		if assigneeID == task.CurrentAssigneeID {
+			diag.Unassigned = append(diag.Unassigned, task.TaskID)
+			continue
+		}
+		result.TaskAssignee[task.TaskID] = assigneeID
+	}
+
+	return result, diag, nil
+}
+
+// Validate checks p for the obvious ways a caller can build an
+// unsolvable or meaningless Problem, so Solve fails fast with a clear
+// error instead of producing a confusing assignment.
+func Validate(p Problem) error {
+	if p.BoardID == "" {
+		return fmt.Errorf("solver: problem has no board ID")
+	}
+	seen := make(map[string]bool, len(p.Tasks))
+	for _, t := range p.Tasks {
+		if t.TaskID == "" {
+			return fmt.Errorf("solver: task with empty ID")
+		}
+		if seen[t.TaskID] {
+			return fmt.Errorf("solver: duplicate task ID %s", t.TaskID)
+		}
+		seen[t.TaskID] = true
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package telemetry
+
+import (
+	"context"
+
+	"github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// amqpHeaderCarrier adapts amqp091.Table to otel's TextMapCarrier, so the
+// global propagator can read/write trace context directly from/to a
+// delivery or publishing's Headers.
+type amqpHeaderCarrier amqp091.Table
+
+var _ propagation.TextMapCarrier = amqpHeaderCarrier{}
+
+func (c amqpHeaderCarrier) Get(key string) string {
+	v, ok := c[key]
+	if !ok {
+		return ""
+	}
+	s, _ := v.(string)
+	return s
+}
+
+func (c amqpHeaderCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c amqpHeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// InjectAMQPHeaders writes ctx's trace context into headers (creating it
+// if nil), for a Publish call that should let the consumer continue this
+// trace.
+func InjectAMQPHeaders(ctx context.Context, headers amqp091.Table) amqp091.Table {
+	if headers == nil {
+		headers = amqp091.Table{}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, amqpHeaderCarrier(headers))
+	return headers
+}
+
+// ExtractAMQPHeaders returns a context carrying the trace context found in
+// headers, so a consumer's span becomes a child of whatever produced the
+// message instead of starting a new, disconnected trace.
+func ExtractAMQPHeaders(ctx context.Context, headers amqp091.Table) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, amqpHeaderCarrier(headers))
+}
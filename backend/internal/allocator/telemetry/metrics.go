@@ -0,0 +1,81 @@
+// Package telemetry is the allocator's Prometheus metrics and
+// OpenTelemetry tracing setup. The allocator used to expose a JSON blob
+// of its own state on /metrics, which Prometheus can't scrape and which
+// carried no latency/throughput data; this package replaces that with a
+// real promhttp handler plus spans that follow one risk alert across the
+// RabbitMQ hop into the agent and out to the main API.
+package telemetry
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Metrics holds every Prometheus collector the allocator reports.
+// Registered against its own registry (rather than the global default
+// one) so NewMetrics can be called more than once, e.g. in the future if
+// the allocator ever runs metrics in-process tests.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	AlertsReceived   *prometheus.CounterVec
+	ActionsQueued    *prometheus.CounterVec
+	ActionsExecuted  *prometheus.CounterVec
+	ActionsFailed    *prometheus.CounterVec
+	HandleAlertDur   *prometheus.HistogramVec
+	ExecuteActionDur *prometheus.HistogramVec
+	PendingActions   *prometheus.GaugeVec
+	ConsumerLag      *prometheus.GaugeVec
+}
+
+// NewMetrics registers and returns the allocator's collectors.
+func NewMetrics() *Metrics {
+	registry := prometheus.NewRegistry()
+	factory := promauto.With(registry)
+
+	return &Metrics{
+		Registry: registry,
+
+		AlertsReceived: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "kanopt_allocator_alerts_received_total",
+			Help: "Risk alerts received from the risk alert queue, by alert type.",
+		}, []string{"alert_type"}),
+
+		ActionsQueued: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "kanopt_allocator_actions_queued_total",
+			Help: "Agent actions published to the durable action queue, by action type.",
+		}, []string{"action_type"}),
+
+		ActionsExecuted: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "kanopt_allocator_actions_executed_total",
+			Help: "Agent actions successfully executed against the main API, by action type.",
+		}, []string{"action_type"}),
+
+		ActionsFailed: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "kanopt_allocator_actions_failed_total",
+			Help: "Agent action executions that returned an error, by action type.",
+		}, []string{"action_type"}),
+
+		HandleAlertDur: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "kanopt_allocator_handle_alert_duration_seconds",
+			Help:    "Time spent in handleRiskAlert, by alert type.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"alert_type"}),
+
+		ExecuteActionDur: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "kanopt_allocator_execute_action_duration_seconds",
+			Help:    "Time spent in executeAction, by action type.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"action_type"}),
+
+		PendingActions: factory.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "kanopt_allocator_pending_actions",
+			Help: "Actions currently sitting in the durable action queue, by priority queue.",
+		}, []string{"queue"}),
+
+		ConsumerLag: factory.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "kanopt_allocator_consumer_lag",
+			Help: "Messages ready on an allocator-consumed RabbitMQ queue.",
+		}, []string{"queue"}),
+	}
+}
@@ -0,0 +1,40 @@
+// Package analytics holds the shared filter envelope accepted by every
+// analytics handler, so "last quarter, weekly buckets, in Asia/Kolkata"
+// means the same thing regardless of which endpoint you hit.
+package analytics
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Query is the common filter/aggregation envelope accepted by the analytics
+// handlers, either as a POST body or as query parameters.
+type Query struct {
+	CreatedAtStart *time.Time  `json:"created_at_start" form:"created_at_start"`
+	CreatedAtEnd   *time.Time  `json:"created_at_end" form:"created_at_end"`
+	AssigneeIDs    []uuid.UUID `json:"assignee_ids" form:"assignee_ids"`
+	LabelIDs       []string    `json:"label_ids" form:"label_ids"`
+	Priorities     []string    `json:"priorities" form:"priorities"`
+	AggregateUnit  string      `json:"aggregate_unit" form:"aggregate_unit"` // day|week|month|quarter
+	TimeZone       string      `json:"time_zone" form:"time_zone"`           // IANA name, e.g. "America/New_York"
+}
+
+// Normalize fills in defaults so handlers never have to special-case a zero
+// value Query, and resolves the timezone into a *time.Location.
+func (q *Query) Normalize(defaultStart, defaultEnd time.Time, defaultUnit string) (*time.Location, error) {
+	if q.AggregateUnit == "" {
+		q.AggregateUnit = defaultUnit
+	}
+	if q.CreatedAtStart == nil {
+		q.CreatedAtStart = &defaultStart
+	}
+	if q.CreatedAtEnd == nil {
+		q.CreatedAtEnd = &defaultEnd
+	}
+	if q.TimeZone == "" {
+		q.TimeZone = "UTC"
+	}
+	return time.LoadLocation(q.TimeZone)
+}
@@ -0,0 +1,109 @@
+// Package bucketing converts a time range + aggregate unit + IANA timezone
+// into both a Postgres date_trunc SQL expression and the in-memory list of
+// bucket boundaries needed to zero-fill gaps the query didn't return rows for.
+package bucketing
+
+import (
+	"fmt"
+	"time"
+)
+
+// Unit is one of the supported aggregation granularities.
+type Unit string
+
+const (
+	Day     Unit = "day"
+	Week    Unit = "week"
+	Month   Unit = "month"
+	Quarter Unit = "quarter"
+)
+
+func (u Unit) valid() bool {
+	switch u {
+	case Day, Week, Month, Quarter:
+		return true
+	}
+	return false
+}
+
+// SQLExpr returns a Postgres date_trunc expression that truncates `column`
+// (a timestamp column) to the given unit in the given timezone. Every unit,
+// quarter included, maps directly onto a date_trunc field of the same name.
+func SQLExpr(column string, unit Unit, timeZone string) (string, error) {
+	if !unit.valid() {
+		return "", fmt.Errorf("bucketing: unknown unit %q", unit)
+	}
+
+	localColumn := fmt.Sprintf("(%s AT TIME ZONE %s)", column, quoteLiteral(timeZone))
+
+	if unit == Quarter {
+		return fmt.Sprintf("date_trunc('quarter', %s)", localColumn), nil
+	}
+	return fmt.Sprintf("date_trunc('%s', %s)", string(unit), localColumn), nil
+}
+
+func quoteLiteral(s string) string {
+	return "'" + s + "'"
+}
+
+// Boundaries returns the start time of every bucket covering [start, end]
+// in the given location, in ascending order. Callers use this to zero-fill
+// buckets the query found no rows for.
+func Boundaries(start, end time.Time, unit Unit, loc *time.Location) ([]time.Time, error) {
+	if !unit.valid() {
+		return nil, fmt.Errorf("bucketing: unknown unit %q", unit)
+	}
+
+	start = truncate(start.In(loc), unit)
+	end = end.In(loc)
+
+	var boundaries []time.Time
+	for cur := start; !cur.After(end); cur = advance(cur, unit) {
+		boundaries = append(boundaries, cur)
+	}
+	return boundaries, nil
+}
+
+func truncate(t time.Time, unit Unit) time.Time {
+	y, m, d := t.Date()
+	switch unit {
+	case Day:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	case Week:
+		// Monday-start to match Postgres's date_trunc('week', ...), which
+		// is ISO-8601 (Monday=1..Sunday=7) rather than Go's Weekday
+		// (Sunday=0..Saturday=6); shifting Sunday to 6 instead of 0 before
+		// the mod keeps the whole week before the same Monday.
+		weekday := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, m, d-weekday, 0, 0, 0, 0, t.Location())
+	case Month:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	case Quarter:
+		quarterMonth := ((int(m) - 1) / 3) * 3
+		return time.Date(y, time.Month(quarterMonth+1), 1, 0, 0, 0, 0, t.Location())
+	}
+	return t
+}
+
+func advance(t time.Time, unit Unit) time.Time {
+	switch unit {
+	case Day:
+		return t.AddDate(0, 0, 1)
+	case Week:
+		return t.AddDate(0, 0, 7)
+	case Month:
+		return t.AddDate(0, 1, 0)
+	case Quarter:
+		return t.AddDate(0, 3, 0)
+	}
+	return t
+}
+
+// Key formats a bucket boundary the same way across handlers, so the
+// frontend can join on it regardless of which endpoint produced it.
+func Key(t time.Time, unit Unit) string {
+	if unit == Day || unit == Week {
+		return t.Format("2006-01-02")
+	}
+	return t.Format("2006-01")
+}
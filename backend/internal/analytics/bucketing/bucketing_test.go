@@ -0,0 +1,71 @@
+package bucketing
+
+import (
+	"testing"
+	"time"
+)
+
+// TestBoundariesWeekTruncatesToMonday covers the bug where Go's
+// int(t.Weekday()) (Sunday=0) truncated weeks to Sunday while Postgres's
+// date_trunc('week', ...) truncates to Monday (ISO-8601): every day of a
+// given week must produce the same Monday-aligned boundary, the same way a
+// date_trunc('week') column value would for every row in that week.
+func TestBoundariesWeekTruncatesToMonday(t *testing.T) {
+	// The week of 2026-07-20 (Monday) through 2026-07-26 (Sunday).
+	monday := time.Date(2026, 7, 20, 0, 0, 0, 0, time.UTC)
+
+	for offset := 0; offset < 7; offset++ {
+		day := monday.AddDate(0, 0, offset)
+		t.Run(day.Weekday().String(), func(t *testing.T) {
+			boundaries, err := Boundaries(day, day, Week, time.UTC)
+			if err != nil {
+				t.Fatalf("Boundaries returned error: %v", err)
+			}
+			if len(boundaries) != 1 {
+				t.Fatalf("got %d boundaries, want 1", len(boundaries))
+			}
+			if !boundaries[0].Equal(monday) {
+				t.Errorf("Boundaries(%s) = %s, want Monday %s", day.Format("2006-01-02 Mon"), boundaries[0], monday)
+			}
+			if boundaries[0].Weekday() != time.Monday {
+				t.Errorf("bucket boundary %s is not a Monday", boundaries[0])
+			}
+		})
+	}
+}
+
+// TestWeeklyBucketJoinsAcrossWeekdays simulates what GetRiskTrends does:
+// zero-fill bucket keys from Boundaries, then key a "row" whose timestamp
+// falls on an arbitrary day of the week (as a Postgres date_trunc('week')
+// result would). Before the fix, a row landing on any weekday but Sunday
+// truncated to a Monday the seeded map never had a key for, so it silently
+// joined into nothing and the bucket always reported zero.
+func TestWeeklyBucketJoinsAcrossWeekdays(t *testing.T) {
+	rangeStart := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	rangeEnd := time.Date(2026, 7, 31, 0, 0, 0, 0, time.UTC)
+
+	boundaries, err := Boundaries(rangeStart, rangeEnd, Week, time.UTC)
+	if err != nil {
+		t.Fatalf("Boundaries returned error: %v", err)
+	}
+	seeded := make(map[string]int, len(boundaries))
+	for _, b := range boundaries {
+		seeded[Key(b, Week)] = 0
+	}
+
+	// A task created on a Wednesday: this is the bucket boundary Postgres's
+	// date_trunc('week', ...) would assign the row to.
+	wednesday := time.Date(2026, 7, 15, 0, 0, 0, 0, time.UTC)
+	postgresTruncatedBucket := time.Date(2026, 7, 13, 0, 0, 0, 0, time.UTC) // Monday of that week
+
+	key := Key(postgresTruncatedBucket, Week)
+	if _, ok := seeded[key]; !ok {
+		t.Fatalf("row truncated to %s (from %s) has no matching seeded bucket; seeded keys: %v",
+			postgresTruncatedBucket.Format("2006-01-02"), wednesday.Format("2006-01-02 Mon"), seeded)
+	}
+	seeded[key]++
+
+	if seeded[key] != 1 {
+		t.Errorf("bucket %s did not receive the joined row: got count %d, want 1", key, seeded[key])
+	}
+}
@@ -1,17 +1,41 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
+	"kanopt/internal/audit"
+	"kanopt/internal/auth"
+	"kanopt/internal/idempotency"
 	"kanopt/internal/models"
 	"kanopt/internal/messaging"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
+// respondIdempotent marshals payload, caches it under key (a no-op if key
+// is empty, i.e. the caller sent no Idempotency-Key header) so a retried
+// request with the same key replays this response instead of re-running
+// the handler's side effects, then writes it as the HTTP response.
+func respondIdempotent(c *gin.Context, idem *idempotency.Store, key string, status int, payload gin.H) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := idem.Put(c.Request.Context(), key, idempotency.CachedResponse{Status: status, Body: body}); err != nil {
+		c.Header("X-Idempotency-Cache-Error", err.Error())
+	}
+
+	c.Data(status, "application/json; charset=utf-8", body)
+}
+
 func GetSuggestions(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		boardID := c.Query("boardId")
@@ -42,8 +66,14 @@ func GetSuggestions(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
-func ApproveSuggestion(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFunc {
+func ApproveSuggestion(db *gorm.DB, rabbitmq *messaging.RabbitMQ, idem *idempotency.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		idempotencyKey := c.GetHeader("Idempotency-Key")
+		if cached, ok, err := idem.Get(c.Request.Context(), idempotencyKey); err == nil && ok {
+			c.Data(cached.Status, "application/json; charset=utf-8", cached.Body)
+			return
+		}
+
 		id := c.Param("id")
 		suggestionID, err := uuid.Parse(id)
 		if err != nil {
@@ -66,12 +96,28 @@ func ApproveSuggestion(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFun
 			return
 		}
 
-		// Update suggestion status
-		suggestion.Status = "approved"
-		if err := db.Save(&suggestion).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		userID, _ := auth.UserID(c)
+		if !ensureBoardRole(c, db, suggestion.BoardID, userID, "member") {
+			return
+		}
+
+		// Conditional UPDATE: only succeeds if the row is still pending at
+		// suggestion.Version, so two reviewers racing to approve the same
+		// suggestion can't both "win" and create duplicate agent actions.
+		result := db.Model(&models.Suggestion{}).
+			Where("id = ? AND status = ? AND version = ?", suggestion.ID, "pending", suggestion.Version).
+			Updates(map[string]interface{}{"status": "approved", "version": suggestion.Version + 1})
+		if result.Error != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+			return
+		}
+		if result.RowsAffected == 0 {
+			respondIdempotent(c, idem, idempotencyKey, http.StatusConflict, gin.H{"error": "Suggestion was already processed by another request"})
 			return
 		}
+		previousVersion := suggestion.Version
+		suggestion.Status = "approved"
+		suggestion.Version++
 
 		// Create agent action to execute the suggestion
 		agentAction := models.AgentAction{
@@ -80,6 +126,7 @@ func ApproveSuggestion(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFun
 			Description: "Executing approved suggestion: " + suggestion.Title,
 			Data:        suggestion.Data,
 			Status:      "pending",
+			CreatedBy:   userID,
 		}
 
 		if err := db.Create(&agentAction).Error; err != nil {
@@ -87,34 +134,58 @@ func ApproveSuggestion(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFun
 			return
 		}
 
+		correlationID := audit.CorrelationID(c)
+		actor := audit.Actor(c)
+
+		if err := audit.Record(db, audit.Entry{
+			CorrelationID: correlationID,
+			BoardID:       suggestion.BoardID,
+			Actor:         actor,
+			Action:        "suggestion.approved",
+			ResourceType:  "suggestion",
+			ResourceID:    suggestion.ID.String(),
+			Before:        models.JSONMap{"status": "pending", "version": previousVersion},
+			After:         models.JSONMap{"status": "approved", "version": suggestion.Version, "agentActionId": agentAction.ID.String()},
+			Outcome:       "success",
+		}); err != nil {
+			c.Header("X-Audit-Error", err.Error())
+		}
+
 		// Publish event
 		event := messaging.Event{
 			ID:      uuid.New().String(),
 			Type:    "suggestion.approved",
 			BoardID: suggestion.BoardID.String(),
-			UserID:  uuid.New().String(),
+			UserID:  actor,
 			Data: map[string]interface{}{
-				"suggestionId": suggestion.ID,
-				"actionId":     agentAction.ID,
-				"type":         suggestion.Type,
-				"title":        suggestion.Title,
+				"suggestionId":  suggestion.ID,
+				"actionId":      agentAction.ID,
+				"type":          suggestion.Type,
+				"title":         suggestion.Title,
+				"correlationId": correlationID,
 			},
 		}
-		
+
 		if err := rabbitmq.PublishEvent(event); err != nil {
 			c.Header("X-Event-Error", err.Error())
 		}
 
-		c.JSON(http.StatusOK, gin.H{
-			"message":      "Suggestion approved",
-			"suggestion":   suggestion,
-			"agentAction":  agentAction,
+		respondIdempotent(c, idem, idempotencyKey, http.StatusOK, gin.H{
+			"message":     "Suggestion approved",
+			"suggestion":  suggestion,
+			"agentAction": agentAction,
 		})
 	}
 }
 
-func RejectSuggestion(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFunc {
+func RejectSuggestion(db *gorm.DB, rabbitmq *messaging.RabbitMQ, idem *idempotency.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		idempotencyKey := c.GetHeader("Idempotency-Key")
+		if cached, ok, err := idem.Get(c.Request.Context(), idempotencyKey); err == nil && ok {
+			c.Data(cached.Status, "application/json; charset=utf-8", cached.Body)
+			return
+		}
+
 		id := c.Param("id")
 		suggestionID, err := uuid.Parse(id)
 		if err != nil {
@@ -137,31 +208,63 @@ func RejectSuggestion(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFunc
 			return
 		}
 
-		// Update suggestion status
-		suggestion.Status = "rejected"
-		if err := db.Save(&suggestion).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		userID, _ := auth.UserID(c)
+		if !ensureBoardRole(c, db, suggestion.BoardID, userID, "member") {
 			return
 		}
 
+		// Conditional UPDATE, same reasoning as ApproveSuggestion.
+		result := db.Model(&models.Suggestion{}).
+			Where("id = ? AND status = ? AND version = ?", suggestion.ID, "pending", suggestion.Version).
+			Updates(map[string]interface{}{"status": "rejected", "version": suggestion.Version + 1})
+		if result.Error != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+			return
+		}
+		if result.RowsAffected == 0 {
+			respondIdempotent(c, idem, idempotencyKey, http.StatusConflict, gin.H{"error": "Suggestion was already processed by another request"})
+			return
+		}
+		previousVersion := suggestion.Version
+		suggestion.Status = "rejected"
+		suggestion.Version++
+
+		correlationID := audit.CorrelationID(c)
+		actor := audit.Actor(c)
+
+		if err := audit.Record(db, audit.Entry{
+			CorrelationID: correlationID,
+			BoardID:       suggestion.BoardID,
+			Actor:         actor,
+			Action:        "suggestion.rejected",
+			ResourceType:  "suggestion",
+			ResourceID:    suggestion.ID.String(),
+			Before:        models.JSONMap{"status": "pending", "version": previousVersion},
+			After:         models.JSONMap{"status": "rejected", "version": suggestion.Version},
+			Outcome:       "success",
+		}); err != nil {
+			c.Header("X-Audit-Error", err.Error())
+		}
+
 		// Publish event
 		event := messaging.Event{
 			ID:      uuid.New().String(),
 			Type:    "suggestion.rejected",
 			BoardID: suggestion.BoardID.String(),
-			UserID:  uuid.New().String(),
+			UserID:  actor,
 			Data: map[string]interface{}{
-				"suggestionId": suggestion.ID,
-				"type":         suggestion.Type,
-				"title":        suggestion.Title,
+				"suggestionId":  suggestion.ID,
+				"type":          suggestion.Type,
+				"title":         suggestion.Title,
+				"correlationId": correlationID,
 			},
 		}
-		
+
 		if err := rabbitmq.PublishEvent(event); err != nil {
 			c.Header("X-Event-Error", err.Error())
 		}
 
-		c.JSON(http.StatusOK, gin.H{
+		respondIdempotent(c, idem, idempotencyKey, http.StatusOK, gin.H{
 			"message":    "Suggestion rejected",
 			"suggestion": suggestion,
 		})
@@ -196,8 +299,16 @@ func GetAgentActions(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
-func ExecuteAgentAction(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFunc {
+// executeActionRequest carries the optional dry-run flag ExecuteAgentAction
+// accepts either as a JSON body or as a query string (?dryRun=true), so a
+// caller that just wants a preview doesn't have to send a body at all.
+type executeActionRequest struct {
+	DryRun bool `json:"dryRun"`
+}
+
+func ExecuteAgentAction(db *gorm.DB, rabbitmq *messaging.RabbitMQ, logger *logrus.Logger, tracker *ExecutionTracker) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := c.Request.Context()
 		id := c.Param("id")
 		actionID, err := uuid.Parse(id)
 		if err != nil {
@@ -205,6 +316,10 @@ func ExecuteAgentAction(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFu
 			return
 		}
 
+		var req executeActionRequest
+		_ = c.ShouldBindJSON(&req)
+		dryRun := req.DryRun || c.Query("dryRun") == "true"
+
 		var action models.AgentAction
 		if err := db.First(&action, actionID).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
@@ -220,75 +335,274 @@ func ExecuteAgentAction(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFu
 			return
 		}
 
-		// Execute the action based on type
-		var executionResult map[string]interface{}
-		var executionError error
+		userID, _ := auth.UserID(c)
+		if !ensureBoardRole(c, db, action.BoardID, userID, "admin") {
+			return
+		}
 
-		switch action.Type {
-		case "redistribute_tasks":
-			executionResult, executionError = executeTaskRedistribution(db, action)
-		case "adjust_wip_limits":
-			executionResult, executionError = executeWIPAdjustment(db, action)
-		case "create_subtasks":
-			executionResult, executionError = executeSubtaskCreation(db, action)
-		case "reassign_overdue":
-			executionResult, executionError = executeOverdueReassignment(db, action)
-		default:
-			executionError = fmt.Errorf("unknown action type: %s", action.Type)
+		// A dry run is a preview only - it runs the same per-type dispatch
+		// inside a transaction (so a multi-row read is consistent) but never
+		// calls tx.Save/tx.Create, never touches the action's status/rollback
+		// data, and never publishes an execution event, since nothing
+		// actually happened.
+		if dryRun {
+			var previewResult map[string]interface{}
+			var previewErr error
+			txErr := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+				previewResult, _, previewErr = dispatchAgentAction(tx, action, true)
+				return previewErr
+			})
+			if previewErr == nil {
+				previewErr = txErr
+			}
+			if previewErr != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"action": action, "error": previewErr.Error()})
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"action": action, "preview": previewResult})
+			return
+		}
+
+		correlationID := audit.CorrelationID(c)
+		actor := audit.Actor(c)
+
+		executionResult, executionError := runAgentAction(ctx, db, rabbitmq, logger, tracker, &action, correlationID, actor)
+
+		response := gin.H{
+			"message": "Agent action executed",
+			"action":  action,
 		}
 
-		// Update action status
-		now := time.Now()
 		if executionError != nil {
-			action.Status = "failed"
-			action.Data["error"] = executionError.Error()
+			response["error"] = executionError.Error()
+			c.JSON(http.StatusInternalServerError, response)
 		} else {
-			action.Status = "completed"
-			action.Data["result"] = executionResult
-			action.ExecutedAt = &now
+			response["result"] = executionResult
+			c.JSON(http.StatusOK, response)
+		}
+	}
+}
+
+// dispatchAgentAction runs action's per-type execute* helper on tx. It's
+// shared by ExecuteAgentAction's dry-run preview and runAgentAction's real
+// run below, so both take the exact same path through the switch.
+func dispatchAgentAction(tx *gorm.DB, action models.AgentAction, dryRun bool) (map[string]interface{}, models.JSONMap, error) {
+	switch action.Type {
+	case "redistribute_tasks":
+		return executeTaskRedistribution(tx, action, dryRun)
+	case "adjust_wip_limits":
+		return executeWIPAdjustment(tx, action, dryRun)
+	case "create_subtasks":
+		return executeSubtaskCreation(tx, action, dryRun)
+	case "reassign_overdue":
+		return executeOverdueReassignment(tx, action, dryRun)
+	default:
+		return nil, nil, fmt.Errorf("unknown action type: %s", action.Type)
+	}
+}
+
+// runAgentAction executes a pending action to completion: dispatchAgentAction
+// inside one transaction (so a failure partway through a multi-row update
+// rolls back cleanly instead of leaving some tasks redistributed and others
+// not), then updates the action's status/rollback data, records an audit
+// entry, and publishes its completion event. ExecuteAgentAction's handler
+// and scheduler.Scheduler's cron-triggered dispatch (via
+// ExecuteScheduledAction) both call this, so a scheduled run is
+// indistinguishable - in the audit trail and the published event - from one
+// a reviewer triggered by hand. An audit/publish failure is logged rather
+// than returned, matching how ExecuteAgentAction's handler previously
+// treated them as non-fatal (surfaced via a response header, which isn't
+// available to the scheduler's non-HTTP caller).
+//
+// tracker registers the execution for the duration of this call so a
+// graceful shutdown can wait for it to finish instead of having ctx's
+// cancellation (or the process exiting outright) tear the transaction down
+// mid-commit; it rejects the call up front if a shutdown is already
+// draining.
+func runAgentAction(ctx context.Context, db *gorm.DB, rabbitmq *messaging.RabbitMQ, logger *logrus.Logger, tracker *ExecutionTracker, action *models.AgentAction, correlationID, actor string) (map[string]interface{}, error) {
+	done, ok := tracker.Start()
+	if !ok {
+		return nil, fmt.Errorf("server is shutting down, not starting agent action %s", action.ID)
+	}
+	defer done()
+
+	var executionResult map[string]interface{}
+	var rollbackData models.JSONMap
+	var executionError error
+
+	txErr := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		executionResult, rollbackData, executionError = dispatchAgentAction(tx, *action, false)
+		return executionError
+	})
+	if executionError == nil {
+		executionError = txErr
+	}
+
+	now := time.Now()
+	outcome := "success"
+	if executionError != nil {
+		action.Status = "failed"
+		action.Data["error"] = executionError.Error()
+		outcome = "failure"
+	} else {
+		action.Status = "completed"
+		action.Data["result"] = executionResult
+		action.RollbackData = rollbackData
+		action.ExecutedAt = &now
+	}
+
+	if err := db.WithContext(ctx).Save(action).Error; err != nil {
+		return executionResult, err
+	}
+
+	if err := audit.Record(db.WithContext(ctx), audit.Entry{
+		CorrelationID: correlationID,
+		BoardID:       action.BoardID,
+		Actor:         actor,
+		Action:        "agent_action.executed:" + action.Type,
+		ResourceType:  "agent_action",
+		ResourceID:    action.ID.String(),
+		Before:        models.JSONMap{"status": "pending"},
+		After:         models.JSONMap{"status": action.Status, "result": executionResult},
+		Outcome:       outcome,
+	}); err != nil {
+		logger.WithError(err).WithField("action_id", action.ID).Warn("agent action: failed to record audit entry")
+	}
+
+	event := messaging.Event{
+		ID:      uuid.New().String(),
+		Type:    "agent.action.executed",
+		BoardID: action.BoardID.String(),
+		UserID:  actor,
+		Data: map[string]interface{}{
+			"actionId":      action.ID,
+			"actionType":    action.Type,
+			"status":        action.Status,
+			"result":        executionResult,
+			"correlationId": correlationID,
+		},
+	}
+
+	if err := rabbitmq.PublishEvent(event); err != nil {
+		logger.WithError(err).WithField("action_id", action.ID).Warn("agent action: failed to publish completion event")
+	}
+
+	return executionResult, executionError
+}
+
+// ExecuteScheduledAction runs a pending AgentAction to completion outside an
+// HTTP request, through the exact same runAgentAction path
+// ExecuteAgentAction's handler uses. scheduler.Scheduler calls this once it
+// has created the pending action for a due ScheduledAction; actor identifies
+// the schedule in the AuditLog and published event (e.g.
+// "schedule:<scheduledActionId>"). tracker is forwarded to runAgentAction so
+// a scheduler-triggered execution is waited on during graceful shutdown the
+// same as one an HTTP request kicked off.
+func ExecuteScheduledAction(ctx context.Context, db *gorm.DB, rabbitmq *messaging.RabbitMQ, logger *logrus.Logger, tracker *ExecutionTracker, actionID uuid.UUID, correlationID, actor string) (map[string]interface{}, error) {
+	var action models.AgentAction
+	if err := db.WithContext(ctx).First(&action, actionID).Error; err != nil {
+		return nil, err
+	}
+	if action.Status != "pending" {
+		return nil, fmt.Errorf("agent action %s is not pending", actionID)
+	}
+
+	return runAgentAction(ctx, db, rabbitmq, logger, tracker, &action, correlationID, actor)
+}
+
+// RollbackAgentAction reverts a completed action's effects from the
+// RollbackData snapshot ExecuteAgentAction recorded, atomically, and marks
+// the action rolled_back. There's nothing to revert for an action that
+// never completed (pending/failed) or that predates this snapshot being
+// recorded, so both are rejected up front rather than guessed at.
+func RollbackAgentAction(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		actionID, err := uuid.Parse(id)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid action ID"})
+			return
+		}
+
+		var action models.AgentAction
+		if err := db.First(&action, actionID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Agent action not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if action.Status != "completed" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Only a completed action can be rolled back"})
+			return
+		}
+		if len(action.RollbackData) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Action has no rollback data recorded"})
+			return
+		}
+
+		userID, _ := auth.UserID(c)
+		if !ensureBoardRole(c, db, action.BoardID, userID, "admin") {
+			return
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			switch action.Type {
+			case "redistribute_tasks", "reassign_overdue":
+				return rollbackAssigneeChanges(tx, action.RollbackData)
+			case "adjust_wip_limits":
+				return rollbackWIPAdjustment(tx, action.RollbackData)
+			case "create_subtasks":
+				return rollbackSubtaskCreation(tx, action.RollbackData)
+			default:
+				return fmt.Errorf("unknown action type: %s", action.Type)
+			}
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
 
+		action.Status = "rolled_back"
 		if err := db.Save(&action).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		// Publish event
 		event := messaging.Event{
 			ID:      uuid.New().String(),
-			Type:    "agent.action.executed",
+			Type:    "agent.action.rolledback",
 			BoardID: action.BoardID.String(),
-			UserID:  uuid.New().String(),
+			UserID:  audit.Actor(c),
 			Data: map[string]interface{}{
-				"actionId":    action.ID,
-				"actionType":  action.Type,
-				"status":      action.Status,
-				"result":      executionResult,
+				"actionId":   action.ID,
+				"actionType": action.Type,
 			},
 		}
-		
+
 		if err := rabbitmq.PublishEvent(event); err != nil {
 			c.Header("X-Event-Error", err.Error())
 		}
 
-		response := gin.H{
-			"message": "Agent action executed",
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Agent action rolled back",
 			"action":  action,
-		}
-
-		if executionError != nil {
-			response["error"] = executionError.Error()
-			c.JSON(http.StatusInternalServerError, response)
-		} else {
-			response["result"] = executionResult
-			c.JSON(http.StatusOK, response)
-		}
+		})
 	}
 }
 
 // Action execution functions
-
-func executeTaskRedistribution(db *gorm.DB, action models.AgentAction) (map[string]interface{}, error) {
+//
+// Each helper takes dryRun so ExecuteAgentAction can ask for a preview: the
+// read side (which tasks/columns are affected) always runs, but the write
+// calls (tx.Save/tx.Create) are skipped when dryRun is true. On a real run
+// that completes, the returned models.JSONMap is the inverse-operation
+// snapshot persisted as AgentAction.RollbackData - nil whenever there's
+// nothing to undo (dry runs, or a type with no rollback helper).
+
+func executeTaskRedistribution(tx *gorm.DB, action models.AgentAction, dryRun bool) (map[string]interface{}, models.JSONMap, error) {
 	// Get overloaded assignee
 	fromUserID, _ := uuid.Parse(action.Data["fromUserId"].(string))
 	toUserID, _ := uuid.Parse(action.Data["toUserId"].(string))
@@ -296,65 +610,99 @@ func executeTaskRedistribution(db *gorm.DB, action models.AgentAction) (map[stri
 
 	// Find tasks to redistribute
 	var tasks []models.Task
-	err := db.Where("assignee_id = ? AND completed_at IS NULL", fromUserID).
+	err := tx.Where("assignee_id = ? AND completed_at IS NULL", fromUserID).
 		Limit(taskCount).
 		Find(&tasks).Error
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	redistributedTasks := make([]uuid.UUID, 0)
+	affectedTasks := make([]uuid.UUID, 0, len(tasks))
+	previousAssignees := make(map[string]string, len(tasks))
 	for _, task := range tasks {
+		affectedTasks = append(affectedTasks, task.ID)
+		previousAssignees[task.ID.String()] = fromUserID.String()
+
+		if dryRun {
+			continue
+		}
 		task.AssigneeID = &toUserID
-		if err := db.Save(&task).Error; err != nil {
-			continue // Skip failed updates
+		if err := tx.Save(&task).Error; err != nil {
+			return nil, nil, err
 		}
-		redistributedTasks = append(redistributedTasks, task.ID)
 	}
 
-	return map[string]interface{}{
-		"redistributedTasks": redistributedTasks,
+	result := map[string]interface{}{
+		"redistributedTasks": affectedTasks,
 		"fromUserId":         fromUserID,
 		"toUserId":           toUserID,
-		"count":              len(redistributedTasks),
-	}, nil
+		"count":              len(affectedTasks),
+		"dryRun":             dryRun,
+	}
+	if dryRun {
+		return result, nil, nil
+	}
+	return result, models.JSONMap{"previousAssignees": previousAssignees}, nil
 }
 
-func executeWIPAdjustment(db *gorm.DB, action models.AgentAction) (map[string]interface{}, error) {
+func executeWIPAdjustment(tx *gorm.DB, action models.AgentAction, dryRun bool) (map[string]interface{}, models.JSONMap, error) {
 	columnID, _ := uuid.Parse(action.Data["columnId"].(string))
 	newLimit := int(action.Data["newLimit"].(float64))
 
 	var column models.Column
-	if err := db.First(&column, columnID).Error; err != nil {
-		return nil, err
+	if err := tx.First(&column, columnID).Error; err != nil {
+		return nil, nil, err
 	}
 
 	oldLimit := column.WIPLimit
+	result := map[string]interface{}{
+		"columnId": columnID,
+		"oldLimit": oldLimit,
+		"newLimit": newLimit,
+		"dryRun":   dryRun,
+	}
+	if dryRun {
+		return result, nil, nil
+	}
+
 	column.WIPLimit = newLimit
-	if err := db.Save(&column).Error; err != nil {
-		return nil, err
+	if err := tx.Save(&column).Error; err != nil {
+		return nil, nil, err
 	}
 
-	return map[string]interface{}{
-		"columnId":  columnID,
-		"oldLimit":  oldLimit,
-		"newLimit":  newLimit,
-	}, nil
+	return result, models.JSONMap{"columnId": columnID.String(), "previousWIPLimit": oldLimit}, nil
 }
 
-func executeSubtaskCreation(db *gorm.DB, action models.AgentAction) (map[string]interface{}, error) {
+func executeSubtaskCreation(tx *gorm.DB, action models.AgentAction, dryRun bool) (map[string]interface{}, models.JSONMap, error) {
 	parentTaskID, _ := uuid.Parse(action.Data["parentTaskId"].(string))
 	subtasks := action.Data["subtasks"].([]interface{})
 
 	var parentTask models.Task
-	if err := db.First(&parentTask, parentTaskID).Error; err != nil {
-		return nil, err
+	if err := tx.First(&parentTask, parentTaskID).Error; err != nil {
+		return nil, nil, err
+	}
+
+	if dryRun {
+		preview := make([]map[string]interface{}, 0, len(subtasks))
+		for _, subtaskData := range subtasks {
+			subtask := subtaskData.(map[string]interface{})
+			preview = append(preview, map[string]interface{}{
+				"title":       subtask["title"],
+				"description": subtask["description"],
+			})
+		}
+		return map[string]interface{}{
+			"parentTaskId": parentTaskID,
+			"preview":      preview,
+			"count":        len(preview),
+			"dryRun":       true,
+		}, nil, nil
 	}
 
-	createdSubtasks := make([]uuid.UUID, 0)
+	createdSubtasks := make([]uuid.UUID, 0, len(subtasks))
 	for _, subtaskData := range subtasks {
 		subtask := subtaskData.(map[string]interface{})
-		
+
 		newTask := models.Task{
 			BoardID:     parentTask.BoardID,
 			ColumnID:    parentTask.ColumnID,
@@ -365,47 +713,292 @@ func executeSubtaskCreation(db *gorm.DB, action models.AgentAction) (map[string]
 			StoryPoints: 1,
 		}
 
-		if err := db.Create(&newTask).Error; err != nil {
-			continue // Skip failed creations
+		if err := tx.Create(&newTask).Error; err != nil {
+			return nil, nil, err
 		}
 		createdSubtasks = append(createdSubtasks, newTask.ID)
 	}
 
-	return map[string]interface{}{
-		"parentTaskId":     parentTaskID,
-		"createdSubtasks":  createdSubtasks,
-		"count":            len(createdSubtasks),
-	}, nil
+	createdSubtaskIDs := make([]string, len(createdSubtasks))
+	for i, id := range createdSubtasks {
+		createdSubtaskIDs[i] = id.String()
+	}
+
+	result := map[string]interface{}{
+		"parentTaskId":    parentTaskID,
+		"createdSubtasks": createdSubtasks,
+		"count":           len(createdSubtasks),
+		"dryRun":          false,
+	}
+	return result, models.JSONMap{"createdSubtaskIds": createdSubtaskIDs}, nil
+}
+
+// storyPointWeight scales a task's story points into the same units as
+// userLoad.score's open-task-count term, so a handful of small tasks and
+// one large one compete on a level footing for argmin selection below.
+const storyPointWeight = 0.5
+
+// workingHoursPenalty is added to a user's score for a task under
+// consideration while they're outside their configured WorkingHours -
+// enough to make an in-hours user with a somewhat heavier load win the
+// argmin comparison, without excluding the out-of-hours user outright (an
+// overdue task still needs an owner even if every eligible user is
+// currently off shift).
+const workingHoursPenalty = 2.0
+
+// userLoad tracks one board member's running assignment score across the
+// reassignment loop below: seeded from their current open-task/story-point
+// load, then updated in-memory as executeOverdueReassignment hands them
+// tasks, so later tasks in the same run see the load earlier ones created.
+type userLoad struct {
+	user           models.User
+	openTaskCount  int
+	sumStoryPoints int
 }
 
-func executeOverdueReassignment(db *gorm.DB, action models.AgentAction) (map[string]interface{}, error) {
-	// Find overdue tasks
+func (l *userLoad) score() float64 {
+	return float64(l.openTaskCount) + float64(l.sumStoryPoints)*storyPointWeight
+}
+
+func executeOverdueReassignment(tx *gorm.DB, action models.AgentAction, dryRun bool) (map[string]interface{}, models.JSONMap, error) {
 	var overdueTasks []models.Task
-	err := db.Where("due_date < ? AND completed_at IS NULL", time.Now()).
+	err := tx.Where("board_id = ? AND due_date < ? AND completed_at IS NULL", action.BoardID, time.Now()).
 		Find(&overdueTasks).Error
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	// Get available users (simplified logic)
+	// Eligible assignees are this board's members, not every user in the
+	// system - a global round-robin could hand an overdue task to someone
+	// with no access to the board it's on.
+	var members []models.BoardMember
+	if err := tx.Where("board_id = ?", action.BoardID).Find(&members).Error; err != nil {
+		return nil, nil, err
+	}
+	memberIDs := make([]uuid.UUID, len(members))
+	for i, m := range members {
+		memberIDs[i] = m.UserID
+	}
 	var users []models.User
-	db.Find(&users)
+	if len(memberIDs) > 0 {
+		if err := tx.Where("id IN ?", memberIDs).Find(&users).Error; err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// One grouped query for every member's current open-task count and
+	// story-point load, instead of a query per candidate per task.
+	type loadRow struct {
+		AssigneeID     uuid.UUID
+		OpenTaskCount  int
+		SumStoryPoints int
+	}
+	var loadRows []loadRow
+	if len(memberIDs) > 0 {
+		if err := tx.Model(&models.Task{}).
+			Select("assignee_id, COUNT(*) AS open_task_count, COALESCE(SUM(story_points), 0) AS sum_story_points").
+			Where("board_id = ? AND completed_at IS NULL AND assignee_id IN ?", action.BoardID, memberIDs).
+			Group("assignee_id").
+			Scan(&loadRows).Error; err != nil {
+			return nil, nil, err
+		}
+	}
+	loadByUser := make(map[uuid.UUID]*userLoad, len(users))
+	for _, u := range users {
+		loadByUser[u.ID] = &userLoad{user: u}
+	}
+	for _, row := range loadRows {
+		if l, ok := loadByUser[row.AssigneeID]; ok {
+			l.openTaskCount = row.OpenTaskCount
+			l.sumStoryPoints = row.SumStoryPoints
+		}
+	}
+
+	now := time.Now()
+	affectedTasks := make([]uuid.UUID, 0, len(overdueTasks))
+	previousAssignees := make(map[string]string, len(overdueTasks))
+	userDeltas := make(map[string]map[string]interface{}, len(users))
 
-	reassignedTasks := make([]uuid.UUID, 0)
 	for _, task := range overdueTasks {
-		// Simple round-robin assignment
-		if len(users) > 0 {
-			newAssignee := users[len(reassignedTasks)%len(users)]
-			task.AssigneeID = &newAssignee.ID
-			if err := db.Save(&task).Error; err != nil {
-				continue
+		best := pickLeastLoaded(loadByUser, task, now)
+		if best == nil {
+			// Every candidate is either missing or over their Capacity cap -
+			// nothing sensible to reassign this task to.
+			continue
+		}
+
+		if task.AssigneeID != nil {
+			previousAssignees[task.ID.String()] = task.AssigneeID.String()
+		} else {
+			previousAssignees[task.ID.String()] = ""
+		}
+		affectedTasks = append(affectedTasks, task.ID)
+
+		// Update the running score in-memory before the next task is
+		// considered, so reassignment within one run spreads load instead of
+		// dog-piling the single best-scored candidate.
+		best.openTaskCount++
+		best.sumStoryPoints += task.StoryPoints
+
+		delta := userDeltas[best.user.ID.String()]
+		if delta == nil {
+			delta = map[string]interface{}{"tasksAdded": 0, "storyPointsAdded": 0}
+			userDeltas[best.user.ID.String()] = delta
+		}
+		delta["tasksAdded"] = delta["tasksAdded"].(int) + 1
+		delta["storyPointsAdded"] = delta["storyPointsAdded"].(int) + task.StoryPoints
+
+		if dryRun {
+			continue
+		}
+		task.AssigneeID = &best.user.ID
+		if err := tx.Save(&task).Error; err != nil {
+			return nil, nil, err
+		}
+	}
+
+	result := map[string]interface{}{
+		"reassignedTasks": affectedTasks,
+		"count":           len(affectedTasks),
+		"userLoadDeltas":  userDeltas,
+		"dryRun":          dryRun,
+	}
+	if dryRun {
+		return result, nil, nil
+	}
+	return result, models.JSONMap{"previousAssignees": previousAssignees}, nil
+}
+
+// pickLeastLoaded returns the candidate in loadByUser with the lowest score
+// once task's story points are added to it, skipping anyone whose resulting
+// score would exceed their Capacity (0 means no cap) and de-prioritizing
+// (not excluding) anyone currently outside their WorkingHours. Returns nil
+// if every candidate is over capacity.
+func pickLeastLoaded(loadByUser map[uuid.UUID]*userLoad, task models.Task, now time.Time) *userLoad {
+	var best *userLoad
+	var bestScore float64
+
+	for _, l := range loadByUser {
+		projected := l.score() + float64(task.StoryPoints)*storyPointWeight
+		if l.user.Capacity > 0 && projected > float64(l.user.Capacity) {
+			continue
+		}
+		if !withinWorkingHours(l.user.WorkingHours, now) {
+			projected += workingHoursPenalty
+		}
+		if best == nil || projected < bestScore {
+			best = l
+			bestScore = projected
+		}
+	}
+
+	return best
+}
+
+// withinWorkingHours reports whether now falls inside hours' configured
+// {"start":"HH:MM","end":"HH:MM","timezone":"..."} window. A nil/empty or
+// unparseable hours fails open (true) - a user who hasn't set working hours
+// is treated as always available, the same as before this field existed.
+func withinWorkingHours(hours models.JSONMap, now time.Time) bool {
+	if len(hours) == 0 {
+		return true
+	}
+
+	start, _ := hours["start"].(string)
+	end, _ := hours["end"].(string)
+	if start == "" || end == "" {
+		return true
+	}
+
+	loc := time.UTC
+	if tz, _ := hours["timezone"].(string); tz != "" {
+		if l, err := time.LoadLocation(tz); err == nil {
+			loc = l
+		}
+	}
+
+	startTime, err := time.Parse("15:04", start)
+	if err != nil {
+		return true
+	}
+	endTime, err := time.Parse("15:04", end)
+	if err != nil {
+		return true
+	}
+
+	local := now.In(loc)
+	minutesNow := local.Hour()*60 + local.Minute()
+	minutesStart := startTime.Hour()*60 + startTime.Minute()
+	minutesEnd := endTime.Hour()*60 + endTime.Minute()
+	return minutesNow >= minutesStart && minutesNow < minutesEnd
+}
+
+// Rollback functions, mirroring the snapshots the execute* helpers above
+// record into AgentAction.RollbackData.
+
+func rollbackAssigneeChanges(tx *gorm.DB, rollback models.JSONMap) error {
+	previousAssignees, ok := rollback["previousAssignees"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("rollback: missing previousAssignees")
+	}
+
+	for taskIDStr, prevAssignee := range previousAssignees {
+		taskID, err := uuid.Parse(taskIDStr)
+		if err != nil {
+			return err
+		}
+
+		prevAssigneeStr, _ := prevAssignee.(string)
+		var assigneeID *uuid.UUID
+		if prevAssigneeStr != "" {
+			parsed, err := uuid.Parse(prevAssigneeStr)
+			if err != nil {
+				return err
 			}
-			reassignedTasks = append(reassignedTasks, task.ID)
+			assigneeID = &parsed
 		}
+
+		if err := tx.Model(&models.Task{}).Where("id = ?", taskID).Update("assignee_id", assigneeID).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func rollbackWIPAdjustment(tx *gorm.DB, rollback models.JSONMap) error {
+	columnIDStr, _ := rollback["columnId"].(string)
+	columnID, err := uuid.Parse(columnIDStr)
+	if err != nil {
+		return err
+	}
+
+	previousWIPLimit, ok := rollback["previousWIPLimit"].(float64)
+	if !ok {
+		return fmt.Errorf("rollback: missing previousWIPLimit")
+	}
+
+	return tx.Model(&models.Column{}).Where("id = ?", columnID).Update("wip_limit", int(previousWIPLimit)).Error
+}
+
+func rollbackSubtaskCreation(tx *gorm.DB, rollback models.JSONMap) error {
+	rawIDs, ok := rollback["createdSubtaskIds"].([]interface{})
+	if !ok {
+		return fmt.Errorf("rollback: missing createdSubtaskIds")
+	}
+
+	ids := make([]uuid.UUID, 0, len(rawIDs))
+	for _, raw := range rawIDs {
+		idStr, _ := raw.(string)
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil
 	}
 
-	return map[string]interface{}{
-		"reassignedTasks": reassignedTasks,
-		"count":           len(reassignedTasks),
-	}, nil
+	return tx.Where("id IN ?", ids).Delete(&models.Task{}).Error
 }
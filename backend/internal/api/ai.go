@@ -1,30 +1,31 @@
 package api
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
 	"net/http"
-	"kanopt/internal/models"
-	"kanopt/internal/messaging"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+
+	"kanopt/internal/ai"
+	"kanopt/internal/models"
+	"kanopt/internal/querystats"
 )
 
 type PredictionRequest struct {
-	TimeHorizon string `json:"timeHorizon"` // "1week", "2weeks", "1month"
-	Metrics     []string `json:"metrics"`   // "velocity", "completion", "risk"
+	TimeHorizon string   `json:"timeHorizon"` // "1week", "2weeks", "1month"
+	Metrics     []string `json:"metrics"`     // "velocity", "completion", "risk"
 }
 
 type PredictionResponse struct {
-	BoardID        uuid.UUID                 `json:"boardId"`
-	TimeHorizon    string                    `json:"timeHorizon"`
-	Predictions    map[string]interface{}    `json:"predictions"`
-	Confidence     float64                   `json:"confidence"`
-	GeneratedAt    string                    `json:"generatedAt"`
-	ModelVersion   string                    `json:"modelVersion"`
+	BoardID      uuid.UUID           `json:"boardId"`
+	TimeHorizon  string              `json:"timeHorizon"`
+	Predictions  ai.PredictionResult `json:"predictions"`
+	Confidence   float64             `json:"confidence"`
+	GeneratedAt  string              `json:"generatedAt"`
+	ModelVersion string              `json:"modelVersion"`
 }
 
 type RiskAnalysisRequest struct {
@@ -33,14 +34,15 @@ type RiskAnalysisRequest struct {
 }
 
 type RiskAnalysisResponse struct {
-	BoardID     uuid.UUID                    `json:"boardId"`
-	Risks       []models.RiskPrediction      `json:"risks"`
-	Summary     map[string]interface{}       `json:"summary"`
-	Recommendations []string                 `json:"recommendations"`
+	BoardID         uuid.UUID               `json:"boardId"`
+	Risks           []models.RiskPrediction `json:"risks"`
+	Summary         ai.RiskSummary          `json:"summary"`
+	Recommendations []string                `json:"recommendations"`
 }
 
-func GetPredictions(db *gorm.DB) gin.HandlerFunc {
+func GetPredictions(db *gorm.DB, aiClient *ai.Client) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		prepStart := time.Now()
 		id := c.Param("id")
 		boardID, err := uuid.Parse(id)
 		if err != nil {
@@ -57,6 +59,12 @@ func GetPredictions(db *gorm.DB) gin.HandlerFunc {
 			}
 		}
 
+		stats, _ := querystats.FromContext(c.Request.Context())
+		if stats != nil {
+			stats.AddQueryPreparation(time.Since(prepStart))
+		}
+		db := db.WithContext(c.Request.Context())
+
 		// Get historical data for predictions
 		var velocityMetrics []models.VelocityMetric
 		db.Where("board_id = ?", boardID).
@@ -67,36 +75,61 @@ func GetPredictions(db *gorm.DB) gin.HandlerFunc {
 		var tasks []models.Task
 		db.Where("board_id = ?", boardID).Find(&tasks)
 
-		// Prepare data for AI service
-		aiRequest := map[string]interface{}{
-			"boardId":         boardID,
-			"timeHorizon":     request.TimeHorizon,
-			"metrics":         request.Metrics,
-			"velocityHistory": velocityMetrics,
-			"currentTasks":    tasks,
+		if stats != nil {
+			stats.AddRowsReturned(int64(len(velocityMetrics) + len(tasks)))
 		}
 
-		// Call AI service
-		predictions, err := callAIService("/api/predict", aiRequest)
+		aiRequest := ai.PredictionRequest{
+			BoardID:         boardID,
+			TimeHorizon:     request.TimeHorizon,
+			Metrics:         request.Metrics,
+			VelocityHistory: velocityMetrics,
+			CurrentTasks:    tasks,
+			DataVersion:     dataVersion(velocityMetrics, tasks),
+		}
+
+		// Call AI service, falling back to simple rule-based predictions if
+		// it's unavailable or the circuit breaker is open.
+		predictions, cacheInfo, err := aiClient.Predict(c.Request.Context(), aiRequest)
 		if err != nil {
-			// Fallback to simple predictions if AI service is unavailable
-			predictions = generateFallbackPredictions(velocityMetrics, tasks, request.TimeHorizon)
+			fallback := generateFallbackPredictions(velocityMetrics, tasks, request.TimeHorizon)
+			predictions = &fallback
+			cacheInfo = ai.CacheInfo{}
+		}
+		if stats != nil {
+			stats.SetCache(cacheInfo.Hit, cacheInfo.TTLRemaining)
 		}
 
 		response := PredictionResponse{
 			BoardID:      boardID,
 			TimeHorizon:  request.TimeHorizon,
-			Predictions:  predictions,
+			Predictions:  *predictions,
 			Confidence:   0.75,
 			GeneratedAt:  "2024-01-15T10:30:00Z",
 			ModelVersion: "v1.2.0",
 		}
 
-		c.JSON(http.StatusOK, response)
+		querystats.Respond(c, http.StatusOK, response)
 	}
 }
 
-func AnalyzeRisk(db *gorm.DB) gin.HandlerFunc {
+// dataVersion is a cheap fingerprint of the data an AI request is based on,
+// so the prediction cache invalidates itself when the board's tasks or
+// velocity history change instead of only expiring on a timer.
+func dataVersion(metrics []models.VelocityMetric, tasks []models.Task) string {
+	var latestTaskUpdate, latestMetric string
+	for _, t := range tasks {
+		if s := t.UpdatedAt.String(); s > latestTaskUpdate {
+			latestTaskUpdate = s
+		}
+	}
+	if len(metrics) > 0 {
+		latestMetric = metrics[0].UpdatedAt.String()
+	}
+	return fmt.Sprintf("%d-%s-%s", len(tasks), latestTaskUpdate, latestMetric)
+}
+
+func AnalyzeRisk(db *gorm.DB, aiClient *ai.Client, persister *ai.RiskPersister) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 		boardID, err := uuid.Parse(id)
@@ -107,10 +140,10 @@ func AnalyzeRisk(db *gorm.DB) gin.HandlerFunc {
 
 		var request RiskAnalysisRequest
 		if err := c.ShouldBindJSON(&request); err != nil {
-			// Analyze all tasks if none specified
+			// Analyze all open tasks if none specified
 			var allTasks []models.Task
 			db.Where("board_id = ? AND completed_at IS NULL", boardID).Find(&allTasks)
-			
+
 			for _, task := range allTasks {
 				request.TaskIDs = append(request.TaskIDs, task.ID)
 			}
@@ -120,50 +153,42 @@ func AnalyzeRisk(db *gorm.DB) gin.HandlerFunc {
 		var tasks []models.Task
 		db.Where("id IN ?", request.TaskIDs).Preload("Assignee").Find(&tasks)
 
-		// Prepare data for AI service
-		aiRequest := map[string]interface{}{
-			"boardId": boardID,
-			"tasks":   tasks,
-			"factors": request.Factors,
+		aiRequest := ai.RiskAnalysisRequest{
+			BoardID: boardID,
+			Tasks:   tasks,
+			Factors: request.Factors,
 		}
 
-		// Call AI service for risk analysis
-		aiResponse, err := callAIService("/api/analyze-risk", aiRequest)
+		result, err := aiClient.AnalyzeRisk(c.Request.Context(), aiRequest)
 		if err != nil {
-			// Fallback to rule-based risk analysis
-			aiResponse = generateFallbackRiskAnalysis(tasks, request.Factors)
+			fallback := generateFallbackRiskAnalysis(tasks, request.Factors)
+			result = &fallback
 		}
 
-		// Create risk predictions in database
-		var risks []models.RiskPrediction
-		if riskData, ok := aiResponse["risks"].([]interface{}); ok {
-			for _, risk := range riskData {
-				if riskMap, ok := risk.(map[string]interface{}); ok {
-					riskPrediction := models.RiskPrediction{
-						BoardID:     boardID,
-						Type:        riskMap["type"].(string),
-						Level:       riskMap["level"].(string),
-						Score:       riskMap["score"].(float64),
-						Description: riskMap["description"].(string),
-						Data:        riskMap,
-					}
-					
-					if taskID, exists := riskMap["taskId"]; exists {
-						if taskUUID, err := uuid.Parse(taskID.(string)); err == nil {
-							riskPrediction.TaskID = &taskUUID
-						}
-					}
-					
-					db.Create(&riskPrediction)
-					risks = append(risks, riskPrediction)
-				}
+		// Assign IDs up front so we can respond immediately; the persister
+		// flushes the actual inserts in a batched transaction.
+		risks := make([]models.RiskPrediction, 0, len(result.Risks))
+		for _, item := range result.Risks {
+			riskPrediction := models.RiskPrediction{
+				ID:          uuid.New(),
+				BoardID:     boardID,
+				TaskID:      item.TaskID,
+				Type:        item.Type,
+				Level:       item.Level,
+				Score:       item.Score,
+				Description: item.Description,
+				Data: models.JSONMap{
+					"factors": item.Factors,
+				},
 			}
+			persister.Enqueue(riskPrediction)
+			risks = append(risks, riskPrediction)
 		}
 
 		response := RiskAnalysisResponse{
-			BoardID:     boardID,
-			Risks:       risks,
-			Summary:     aiResponse["summary"].(map[string]interface{}),
+			BoardID: boardID,
+			Risks:   risks,
+			Summary: result.Summary,
 			Recommendations: []string{
 				"Consider redistributing tasks from overloaded team members",
 				"Review tasks approaching deadlines for scope reduction",
@@ -175,46 +200,40 @@ func AnalyzeRisk(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
-// Helper functions
-
-func callAIService(endpoint string, data map[string]interface{}) (map[string]interface{}, error) {
-	// TODO: Get AI service URL from config
-	aiServiceURL := "http://localhost:8000"
-	
-	jsonData, err := json.Marshal(data)
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := http.Post(aiServiceURL+endpoint, "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, err
+// AIHealth reports whether the AI service's circuit breaker is open, so
+// operators can tell "predictions are slow" apart from "predictions are
+// running on the fallback".
+func AIHealth(aiClient *ai.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		state := aiClient.BreakerState()
+		status := http.StatusOK
+		if state == ai.StateOpen {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, gin.H{"breakerState": state})
 	}
-	defer resp.Body.Close()
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("AI service returned status %d", resp.StatusCode)
+// AIMetrics reports call counts and latency percentiles for the AI client.
+func AIMetrics(aiClient *ai.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, aiClient.Metrics())
 	}
+}
 
-	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
-	}
+// Helper functions
 
-	return result, nil
-}
+func generateFallbackPredictions(metrics []models.VelocityMetric, tasks []models.Task, timeHorizon string) ai.PredictionResult {
+	var predictions ai.PredictionResult
 
-func generateFallbackPredictions(metrics []models.VelocityMetric, tasks []models.Task, timeHorizon string) map[string]interface{} {
-	predictions := make(map[string]interface{})
-	
 	// Calculate simple velocity prediction
 	if len(metrics) > 0 {
 		currentVelocity := metrics[0].Velocity
-		predictions["velocity"] = map[string]interface{}{
-			"predicted": currentVelocity * 1.05, // Slight improvement
-			"range": map[string]float64{
-				"min": currentVelocity * 0.8,
-				"max": currentVelocity * 1.2,
+		predictions.Velocity = &ai.VelocityPrediction{
+			Predicted: currentVelocity * 1.05, // Slight improvement
+			Range: ai.MinMax{
+				Min: currentVelocity * 0.8,
+				Max: currentVelocity * 1.2,
 			},
 		}
 	}
@@ -238,80 +257,87 @@ func generateFallbackPredictions(metrics []models.VelocityMetric, tasks []models
 	}
 
 	expectedCompletion := float64(activeTasks) * 0.7 // Assume 70% completion rate
-	predictions["completion"] = map[string]interface{}{
-		"expectedTasks":  int(expectedCompletion),
-		"totalTasks":     activeTasks,
-		"storyPoints":    int(float64(totalStoryPoints) * 0.7),
-		"completionRate": 0.7,
+	predictions.Completion = &ai.CompletionPrediction{
+		ExpectedTasks:  int(expectedCompletion),
+		TotalTasks:     activeTasks,
+		StoryPoints:    int(float64(totalStoryPoints) * 0.7),
+		CompletionRate: 0.7,
+		DaysInHorizon:  daysInHorizon,
 	}
 
 	// Risk prediction
-	predictions["risk"] = map[string]interface{}{
-		"overallRisk": "medium",
-		"riskFactors": []string{
+	predictions.Risk = &ai.RiskSummaryPrediction{
+		OverallRisk: "medium",
+		RiskFactors: []string{
 			"High work in progress",
 			"Approaching deadlines",
 		},
-		"riskScore": 0.6,
+		RiskScore: 0.6,
 	}
 
 	return predictions
 }
 
-func generateFallbackRiskAnalysis(tasks []models.Task, factors []string) map[string]interface{} {
-	risks := make([]interface{}, 0)
-	
+func generateFallbackRiskAnalysis(tasks []models.Task, factors []string) ai.RiskAnalysisResult {
+	risks := make([]ai.RiskItem, 0)
+
 	for _, task := range tasks {
 		riskScore := 0.0
 		riskLevel := "low"
 		riskFactors := make([]string, 0)
-		
+
 		// Check deadline factor
 		if task.DueDate != nil && task.DueDate.Before(task.CreatedAt.AddDate(0, 0, 7)) {
 			riskScore += 0.3
 			riskFactors = append(riskFactors, "tight_deadline")
 		}
-		
+
 		// Check complexity (based on story points)
 		if task.StoryPoints > 8 {
 			riskScore += 0.2
 			riskFactors = append(riskFactors, "high_complexity")
 		}
-		
+
 		// Check if unassigned
 		if task.AssigneeID == nil {
 			riskScore += 0.3
 			riskFactors = append(riskFactors, "unassigned")
 		}
-		
+
 		// Determine risk level
 		if riskScore >= 0.7 {
 			riskLevel = "high"
 		} else if riskScore >= 0.4 {
 			riskLevel = "medium"
 		}
-		
+
 		if riskScore > 0.1 {
-			risks = append(risks, map[string]interface{}{
-				"taskId":      task.ID.String(),
-				"type":        "delivery_risk",
-				"level":       riskLevel,
-				"score":       riskScore,
-				"description": fmt.Sprintf("Task '%s' has elevated risk factors", task.Title),
-				"factors":     riskFactors,
+			taskID := task.ID
+			risks = append(risks, ai.RiskItem{
+				TaskID:      &taskID,
+				Type:        "delivery_risk",
+				Level:       riskLevel,
+				Score:       riskScore,
+				Description: fmt.Sprintf("Task '%s' has elevated risk factors", task.Title),
+				Factors:     riskFactors,
 			})
 		}
 	}
-	
-	summary := map[string]interface{}{
-		"totalTasks":    len(tasks),
-		"riskyTasks":    len(risks),
-		"averageRisk":   0.3,
-		"highRiskCount": 0,
+
+	highRiskCount := 0
+	for _, r := range risks {
+		if r.Level == "high" {
+			highRiskCount++
+		}
 	}
-	
-	return map[string]interface{}{
-		"risks":   risks,
-		"summary": summary,
+
+	return ai.RiskAnalysisResult{
+		Risks: risks,
+		Summary: ai.RiskSummary{
+			TotalTasks:    len(tasks),
+			RiskyTasks:    len(risks),
+			AverageRisk:   0.3,
+			HighRiskCount: highRiskCount,
+		},
 	}
 }
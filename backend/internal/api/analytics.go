@@ -1,52 +1,105 @@
 package api
 
 import (
+	"kanopt/internal/analytics"
+	"kanopt/internal/analytics/bucketing"
+	"kanopt/internal/models"
+	"kanopt/internal/querystats"
+	"kanopt/internal/sprint"
 	"net/http"
 	"time"
-	"kanopt/internal/models"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// parseAnalyticsQuery binds the shared filter/aggregation envelope from
+// either a POST body or query parameters, so all analytics handlers accept
+// the same `filters + aggregate_unit + time_zone` shape.
+func parseAnalyticsQuery(c *gin.Context) (*analytics.Query, error) {
+	var query analytics.Query
+	var err error
+	if c.Request.Method == http.MethodPost {
+		err = c.ShouldBindJSON(&query)
+	} else {
+		err = c.ShouldBindQuery(&query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &query, nil
+}
+
+// applyTaskFilters narrows a *models.Task query by the assignee/label/
+// priority/created-at filters in an analytics.Query, if set.
+func applyTaskFilters(q *gorm.DB, query *analytics.Query) *gorm.DB {
+	if len(query.AssigneeIDs) > 0 {
+		q = q.Where("tasks.assignee_id IN ?", query.AssigneeIDs)
+	}
+	if len(query.Priorities) > 0 {
+		q = q.Where("tasks.priority IN ?", query.Priorities)
+	}
+	if len(query.LabelIDs) > 0 {
+		// Tags are stored as a JSON array; ?| checks for overlap with any
+		// of the requested labels.
+		q = q.Where("tasks.tags ?| array[?]", query.LabelIDs)
+	}
+	if query.CreatedAtStart != nil {
+		q = q.Where("tasks.created_at >= ?", *query.CreatedAtStart)
+	}
+	if query.CreatedAtEnd != nil {
+		q = q.Where("tasks.created_at <= ?", *query.CreatedAtEnd)
+	}
+	return q
+}
+
 type VelocityResponse struct {
-	CurrentVelocity   float64                `json:"currentVelocity"`
-	AverageVelocity   float64                `json:"averageVelocity"`
-	VelocityTrend     string                 `json:"velocityTrend"`
+	CurrentVelocity   float64                 `json:"currentVelocity"`
+	AverageVelocity   float64                 `json:"averageVelocity"`
+	VelocityTrend     string                  `json:"velocityTrend"`
 	WeeklyMetrics     []models.VelocityMetric `json:"weeklyMetrics"`
-	PredictedVelocity float64                `json:"predictedVelocity"`
+	PredictedVelocity float64                 `json:"predictedVelocity"`
 }
 
 type BurndownData struct {
-	Date           string  `json:"date"`
-	Remaining      int     `json:"remaining"`
-	Ideal          int     `json:"ideal"`
-	Actual         int     `json:"actual"`
-	TotalStoryPoints int   `json:"totalStoryPoints"`
+	Date             string `json:"date"`
+	Remaining        int    `json:"remaining"`
+	Ideal            int    `json:"ideal"`
+	Actual           int    `json:"actual"`
+	TotalStoryPoints int    `json:"totalStoryPoints"`
+	ScopeAdded       int    `json:"scopeAdded"`
+	ScopeRemoved     int    `json:"scopeRemoved"`
+}
+
+type BurndownResponse struct {
+	SprintID uuid.UUID      `json:"sprintId"`
+	Sprint   models.Sprint  `json:"sprint"`
+	Days     []BurndownData `json:"days"`
 }
 
 type RiskTrendData struct {
-	Date      string  `json:"date"`
-	HighRisk  int     `json:"highRisk"`
+	Date       string `json:"date"`
+	HighRisk   int    `json:"highRisk"`
 	MediumRisk int    `json:"mediumRisk"`
-	LowRisk   int     `json:"lowRisk"`
+	LowRisk    int    `json:"lowRisk"`
 	TotalTasks int    `json:"totalTasks"`
 }
 
 type TeamPerformanceData struct {
-	UserID          uuid.UUID `json:"userId"`
-	Name            string    `json:"name"`
-	Avatar          string    `json:"avatar"`
-	CompletedTasks  int       `json:"completedTasks"`
-	TotalStoryPoints int      `json:"totalStoryPoints"`
-	AverageCycleTime float64  `json:"averageCycleTime"`
-	Velocity        float64   `json:"velocity"`
-	EfficiencyScore float64   `json:"efficiencyScore"`
+	UserID           uuid.UUID `json:"userId"`
+	Name             string    `json:"name"`
+	Avatar           string    `json:"avatar"`
+	CompletedTasks   int       `json:"completedTasks"`
+	TotalStoryPoints int       `json:"totalStoryPoints"`
+	AverageCycleTime float64   `json:"averageCycleTime"`
+	Velocity         float64   `json:"velocity"`
+	EfficiencyScore  float64   `json:"efficiencyScore"`
 }
 
 func GetVelocityMetrics(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		prepStart := time.Now()
 		id := c.Param("id")
 		boardID, err := uuid.Parse(id)
 		if err != nil {
@@ -54,18 +107,51 @@ func GetVelocityMetrics(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		// Get velocity metrics for the last 12 weeks
+		query, err := parseAnalyticsQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		// Velocity is reported per sprint, so aggregate_unit doesn't bucket
+		// anything here; created_at_start/end narrow which sprints count,
+		// same as every other analytics handler.
+		if _, err := query.Normalize(time.Time{}, time.Now(), "day"); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time_zone: " + err.Error()})
+			return
+		}
+
+		stats, _ := querystats.FromContext(c.Request.Context())
+		if stats != nil {
+			stats.AddQueryPreparation(time.Since(prepStart))
+		}
+		db := db.WithContext(c.Request.Context())
+
+		// Prefer aggregating one VelocityMetric row per closed sprint; fall
+		// back to the legacy sprint_week heuristic for boards with no
+		// sprints yet.
 		var metrics []models.VelocityMetric
-		err = db.Where("board_id = ?", boardID).
-			Order("sprint_week DESC").
+		err = db.Where("velocity_metrics.board_id = ? AND sprint_id IS NOT NULL", boardID).
+			Where("sprints.end_at BETWEEN ? AND ?", *query.CreatedAtStart, *query.CreatedAtEnd).
+			Joins("JOIN sprints ON sprints.id = velocity_metrics.sprint_id").
+			Order("sprints.end_at DESC").
 			Limit(12).
 			Find(&metrics).Error
-		
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
+		if len(metrics) == 0 {
+			err = db.Where("board_id = ? AND created_at BETWEEN ? AND ?", boardID, *query.CreatedAtStart, *query.CreatedAtEnd).
+				Order("sprint_week DESC").
+				Limit(12).
+				Find(&metrics).Error
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
 		// Calculate current and average velocity
 		var currentVelocity, averageVelocity, totalVelocity float64
 		if len(metrics) > 0 {
@@ -102,12 +188,16 @@ func GetVelocityMetrics(db *gorm.DB) gin.HandlerFunc {
 			PredictedVelocity: predictedVelocity,
 		}
 
-		c.JSON(http.StatusOK, response)
+		if stats != nil {
+			stats.AddRowsReturned(int64(len(metrics)))
+		}
+		querystats.Respond(c, http.StatusOK, response)
 	}
 }
 
 func GetBurndownData(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		prepStart := time.Now()
 		id := c.Param("id")
 		boardID, err := uuid.Parse(id)
 		if err != nil {
@@ -115,48 +205,99 @@ func GetBurndownData(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		// Get sprint duration (default to 2 weeks)
-		sprintDays := 14
-		sprintStart := time.Now().AddDate(0, 0, -sprintDays)
+		query, err := parseAnalyticsQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		loc, err := query.Normalize(time.Time{}, time.Time{}, "day")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time_zone: " + err.Error()})
+			return
+		}
+		unit := bucketing.Unit(query.AggregateUnit)
 
-		// Get total story points at sprint start
-		var totalStoryPoints int
-		db.Model(&models.Task{}).
-			Where("board_id = ? AND created_at <= ?", boardID, sprintStart).
-			Select("COALESCE(SUM(story_points), 0)").
-			Scan(&totalStoryPoints)
-
-		var burndownData []BurndownData
-		
-		// Generate burndown data for each day
-		for i := 0; i <= sprintDays; i++ {
-			currentDate := sprintStart.AddDate(0, 0, i)
-			
-			// Calculate remaining points
-			var completedPoints int
-			db.Model(&models.Task{}).
-				Where("board_id = ? AND completed_at <= ?", boardID, currentDate).
-				Select("COALESCE(SUM(story_points), 0)").
-				Scan(&completedPoints)
-			
-			remaining := totalStoryPoints - completedPoints
-			ideal := totalStoryPoints - (totalStoryPoints * i / sprintDays)
-			
-			burndownData = append(burndownData, BurndownData{
-				Date:             currentDate.Format("2006-01-02"),
-				Remaining:        remaining,
-				Ideal:            ideal,
-				Actual:           remaining,
-				TotalStoryPoints: totalStoryPoints,
-			})
+		stats, _ := querystats.FromContext(c.Request.Context())
+		if stats != nil {
+			stats.AddQueryPreparation(time.Since(prepStart))
 		}
+		db := db.WithContext(c.Request.Context())
 
-		c.JSON(http.StatusOK, burndownData)
+		targetSprint, err := resolveSprint(db, boardID, c.Query("sprintId"))
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "No matching sprint found for board"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Make sure today's snapshot exists before reading the series back,
+		// so the chart never has a trailing gap on an active sprint.
+		if targetSprint.Status == models.SprintStatusActive {
+			if err := sprint.Snapshot(db, targetSprint.ID, time.Now()); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		var dayStats []models.SprintDayStat
+		if err := db.Where("sprint_id = ?", targetSprint.ID).Order("date ASC").Find(&dayStats).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		totalDays := int(targetSprint.EndAt.Sub(targetSprint.StartAt).Hours()/24) + 1
+		if totalDays < 1 {
+			totalDays = 1
+		}
+
+		// Sprints are usually a couple of weeks long, so "day" is the
+		// natural unit here, but we still bucket by aggregate_unit for the
+		// same shape every other analytics handler returns. Each bucket
+		// keeps the last remaining-points reading (an end-of-bucket
+		// snapshot) and sums scope changes within it.
+		order := make([]string, 0, len(dayStats))
+		buckets := make(map[string]BurndownData, len(dayStats))
+		for _, stat := range dayStats {
+			dayIndex := int(stat.Date.Sub(targetSprint.StartAt).Hours() / 24)
+			ideal := targetSprint.CommittedStoryPoints - (targetSprint.CommittedStoryPoints * dayIndex / totalDays)
+
+			key := bucketing.Key(stat.Date.In(loc), unit)
+			bucket, ok := buckets[key]
+			if !ok {
+				order = append(order, key)
+				bucket = BurndownData{Date: key, TotalStoryPoints: targetSprint.CommittedStoryPoints}
+			}
+			bucket.Remaining = stat.RemainingPoints
+			bucket.Actual = stat.RemainingPoints
+			bucket.Ideal = ideal
+			bucket.ScopeAdded += stat.ScopeAdded
+			bucket.ScopeRemoved += stat.ScopeRemoved
+			buckets[key] = bucket
+		}
+
+		days := make([]BurndownData, 0, len(order))
+		for _, key := range order {
+			days = append(days, buckets[key])
+		}
+
+		if stats != nil {
+			stats.AddRowsReturned(int64(len(dayStats)))
+			stats.AddBucketsEmitted(int64(len(days)))
+		}
+		querystats.Respond(c, http.StatusOK, BurndownResponse{
+			SprintID: targetSprint.ID,
+			Sprint:   *targetSprint,
+			Days:     days,
+		})
 	}
 }
 
 func GetRiskTrends(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		prepStart := time.Now()
 		id := c.Param("id")
 		boardID, err := uuid.Parse(id)
 		if err != nil {
@@ -164,49 +305,93 @@ func GetRiskTrends(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		// Get risk trends for the last 30 days
-		var riskTrends []RiskTrendData
-		
-		for i := 29; i >= 0; i-- {
-			currentDate := time.Now().AddDate(0, 0, -i)
-			dateStr := currentDate.Format("2006-01-02")
-			
-			// Count risks by level for this date
-			var highRisk, mediumRisk, lowRisk int64
-			
-			db.Model(&models.RiskPrediction{}).
-				Where("board_id = ? AND DATE(created_at) = ? AND level = ?", boardID, dateStr, "high").
-				Count(&highRisk)
-			
-			db.Model(&models.RiskPrediction{}).
-				Where("board_id = ? AND DATE(created_at) = ? AND level = ?", boardID, dateStr, "medium").
-				Count(&mediumRisk)
-			
-			db.Model(&models.RiskPrediction{}).
-				Where("board_id = ? AND DATE(created_at) = ? AND level = ?", boardID, dateStr, "low").
-				Count(&lowRisk)
-			
-			// Count total tasks
-			var totalTasks int64
-			db.Model(&models.Task{}).
-				Where("board_id = ? AND DATE(created_at) <= ?", boardID, dateStr).
-				Count(&totalTasks)
-			
+		query, err := parseAnalyticsQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		now := time.Now()
+		loc, err := query.Normalize(now.AddDate(0, 0, -29), now, "day")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time_zone: " + err.Error()})
+			return
+		}
+		unit := bucketing.Unit(query.AggregateUnit)
+
+		boundaries, err := bucketing.Boundaries(*query.CreatedAtStart, *query.CreatedAtEnd, unit, loc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		bucketExpr, err := bucketing.SQLExpr("created_at", unit, query.TimeZone)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		stats, _ := querystats.FromContext(c.Request.Context())
+		if stats != nil {
+			stats.AddQueryPreparation(time.Since(prepStart))
+		}
+		db := db.WithContext(c.Request.Context())
+
+		type riskRow struct {
+			Bucket time.Time
+			Level  string
+			Count  int64
+		}
+		var rows []riskRow
+		riskQuery := db.Model(&models.RiskPrediction{}).
+			Select(bucketExpr+" AS bucket, level, COUNT(*) AS count").
+			Where("board_id = ? AND created_at BETWEEN ? AND ?", boardID, *query.CreatedAtStart, *query.CreatedAtEnd).
+			Group(bucketExpr + ", level")
+		if err := riskQuery.Scan(&rows).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		counts := make(map[string]map[string]int, len(boundaries))
+		for _, b := range boundaries {
+			counts[bucketing.Key(b, unit)] = map[string]int{}
+		}
+		for _, row := range rows {
+			key := bucketing.Key(row.Bucket, unit)
+			if counts[key] == nil {
+				counts[key] = map[string]int{}
+			}
+			counts[key][row.Level] = int(row.Count)
+		}
+
+		var totalTasks int64
+		db.Model(&models.Task{}).
+			Where("board_id = ? AND created_at <= ?", boardID, *query.CreatedAtEnd).
+			Count(&totalTasks)
+
+		riskTrends := make([]RiskTrendData, 0, len(boundaries))
+		for _, b := range boundaries {
+			key := bucketing.Key(b, unit)
 			riskTrends = append(riskTrends, RiskTrendData{
-				Date:       dateStr,
-				HighRisk:   int(highRisk),
-				MediumRisk: int(mediumRisk),
-				LowRisk:    int(lowRisk),
+				Date:       key,
+				HighRisk:   counts[key]["high"],
+				MediumRisk: counts[key]["medium"],
+				LowRisk:    counts[key]["low"],
 				TotalTasks: int(totalTasks),
 			})
 		}
 
-		c.JSON(http.StatusOK, riskTrends)
+		if stats != nil {
+			stats.AddRowsReturned(int64(len(rows)))
+			stats.AddBucketsEmitted(int64(len(riskTrends)))
+		}
+		querystats.RespondData(c, http.StatusOK, riskTrends)
 	}
 }
 
 func GetTeamPerformance(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		prepStart := time.Now()
 		id := c.Param("id")
 		boardID, err := uuid.Parse(id)
 		if err != nil {
@@ -214,39 +399,55 @@ func GetTeamPerformance(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
+		query, err := parseAnalyticsQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if _, err := query.Normalize(time.Time{}, time.Now(), "day"); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time_zone: " + err.Error()})
+			return
+		}
+
+		stats, _ := querystats.FromContext(c.Request.Context())
+		if stats != nil {
+			stats.AddQueryPreparation(time.Since(prepStart))
+		}
+		db := db.WithContext(c.Request.Context())
+
 		// Get all users who have tasks in this board
 		var users []models.User
-		err = db.Joins("JOIN tasks ON users.id = tasks.assignee_id").
-			Where("tasks.board_id = ?", boardID).
+		err = applyTaskFilters(db.Joins("JOIN tasks ON users.id = tasks.assignee_id").
+			Where("tasks.board_id = ?", boardID), query).
 			Group("users.id").
 			Find(&users).Error
-		
+
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
 		var teamPerformance []TeamPerformanceData
-		
+
 		for _, user := range users {
+			baseQuery := func() *gorm.DB {
+				return applyTaskFilters(db.Where("board_id = ? AND assignee_id = ? AND completed_at IS NOT NULL", boardID, user.ID), query)
+			}
+
 			// Count completed tasks
 			var completedTasks int64
-			db.Model(&models.Task{}).
-				Where("board_id = ? AND assignee_id = ? AND completed_at IS NOT NULL", boardID, user.ID).
-				Count(&completedTasks)
-			
+			baseQuery().Model(&models.Task{}).Count(&completedTasks)
+
 			// Sum story points
 			var totalStoryPoints int
-			db.Model(&models.Task{}).
-				Where("board_id = ? AND assignee_id = ? AND completed_at IS NOT NULL", boardID, user.ID).
+			baseQuery().Model(&models.Task{}).
 				Select("COALESCE(SUM(story_points), 0)").
 				Scan(&totalStoryPoints)
-			
+
 			// Calculate average cycle time
 			var tasks []models.Task
-			db.Where("board_id = ? AND assignee_id = ? AND completed_at IS NOT NULL", boardID, user.ID).
-				Find(&tasks)
-			
+			baseQuery().Find(&tasks)
+
 			var totalCycleTime float64
 			for _, task := range tasks {
 				if task.CompletedAt != nil {
@@ -254,12 +455,12 @@ func GetTeamPerformance(db *gorm.DB) gin.HandlerFunc {
 					totalCycleTime += cycleTime
 				}
 			}
-			
+
 			averageCycleTime := float64(0)
 			if len(tasks) > 0 {
 				averageCycleTime = totalCycleTime / float64(len(tasks))
 			}
-			
+
 			// Calculate velocity (story points per week)
 			velocity := float64(0)
 			if len(tasks) > 0 {
@@ -268,7 +469,7 @@ func GetTeamPerformance(db *gorm.DB) gin.HandlerFunc {
 					velocity = float64(totalStoryPoints) / weeksSinceFirstTask
 				}
 			}
-			
+
 			// Calculate efficiency score (arbitrary formula)
 			efficiencyScore := float64(0)
 			if averageCycleTime > 0 {
@@ -277,7 +478,7 @@ func GetTeamPerformance(db *gorm.DB) gin.HandlerFunc {
 					efficiencyScore = 100
 				}
 			}
-			
+
 			teamPerformance = append(teamPerformance, TeamPerformanceData{
 				UserID:           user.ID,
 				Name:             user.Name,
@@ -290,6 +491,9 @@ func GetTeamPerformance(db *gorm.DB) gin.HandlerFunc {
 			})
 		}
 
-		c.JSON(http.StatusOK, teamPerformance)
+		if stats != nil {
+			stats.AddRowsReturned(int64(len(teamPerformance)))
+		}
+		querystats.RespondData(c, http.StatusOK, teamPerformance)
 	}
 }
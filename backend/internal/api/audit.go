@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+
+	"kanopt/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetAuditLog lets an operator trace a request end-to-end by boardId and/or
+// correlationId - e.g. a suggestion approval, the agent action it created,
+// and the execution/rollback that followed all share one correlation ID.
+func GetAuditLog(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		boardID := c.Query("boardId")
+		correlationID := c.Query("correlationId")
+
+		query := db.Model(&models.AuditLog{})
+
+		if boardID != "" {
+			if id, err := uuid.Parse(boardID); err == nil {
+				query = query.Where("board_id = ?", id)
+			}
+		}
+
+		if correlationID != "" {
+			query = query.Where("correlation_id = ?", correlationID)
+		}
+
+		var entries []models.AuditLog
+		result := query.Order("created_at DESC").Find(&entries)
+		if result.Error != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, entries)
+	}
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"kanopt/internal/auth"
+	"kanopt/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 7 * 24 * time.Hour
+)
+
+// ensureBoardMember rejects the request with 403 unless userID is a member
+// of boardID, returning false so the caller can stop handling the request.
+// Callers that create a BoardMember themselves (CreateBoard) don't need
+// this check.
+func ensureBoardMember(c *gin.Context, db *gorm.DB, boardID, userID uuid.UUID) bool {
+	var count int64
+	if err := db.Model(&models.BoardMember{}).Where("board_id = ? AND user_id = ?", boardID, userID).Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	if count == 0 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a member of this board"})
+		return false
+	}
+	return true
+}
+
+// boardRoleRank orders BoardMember.Role values from least to most
+// privileged so ensureBoardRole can check "at least this role" instead of
+// an exact match.
+var boardRoleRank = map[string]int{
+	"viewer": 0,
+	"member": 1,
+	"admin":  2,
+}
+
+// ensureBoardRole rejects the request with 403 unless userID belongs to
+// boardID with a role at least as privileged as minRole, returning false so
+// the caller can stop handling the request. Unlike ensureBoardMember, which
+// only checks membership, this enforces the viewer/member/admin matrix
+// BoardMember.Role carries.
+func ensureBoardRole(c *gin.Context, db *gorm.DB, boardID, userID uuid.UUID, minRole string) bool {
+	var member models.BoardMember
+	if err := db.Where("board_id = ? AND user_id = ?", boardID, userID).First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not a member of this board"})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	if boardRoleRank[member.Role] < boardRoleRank[minRole] {
+		c.JSON(http.StatusForbidden, gin.H{"error": "requires " + minRole + " role on this board"})
+		return false
+	}
+	return true
+}
+
+func issueTokenPair(manager *auth.Manager, user models.User) (accessToken, refreshToken string, err error) {
+	accessToken, err = manager.IssueAccessToken(user.ID, user.Roles, accessTokenTTL)
+	if err != nil {
+		return "", "", err
+	}
+	refreshToken, err = manager.IssueRefreshToken(user.ID, user.Roles, refreshTokenTTL)
+	if err != nil {
+		return "", "", err
+	}
+	return accessToken, refreshToken, nil
+}
+
+// Register creates a user with a bcrypt-hashed password and returns a
+// token pair, the same shape Login returns.
+func Register(db *gorm.DB, manager *auth.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Name     string `json:"name" binding:"required"`
+			Email    string `json:"email" binding:"required"`
+			Password string `json:"password" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(body.Password), bcrypt.DefaultCost)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		user := models.User{
+			Name:         body.Name,
+			Email:        body.Email,
+			PasswordHash: string(hash),
+			Roles:        models.StringSlice{"member"},
+		}
+		if err := db.Create(&user).Error; err != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+
+		accessToken, refreshToken, err := issueTokenPair(manager, user)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"user": user, "accessToken": accessToken, "refreshToken": refreshToken})
+	}
+}
+
+// Login verifies email/password and returns a token pair.
+func Login(db *gorm.DB, manager *auth.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Email    string `json:"email" binding:"required"`
+			Password string `json:"password" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var user models.User
+		if err := db.Where("email = ?", body.Email).First(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid email or password"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(body.Password)); err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid email or password"})
+			return
+		}
+
+		accessToken, refreshToken, err := issueTokenPair(manager, user)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"user": user, "accessToken": accessToken, "refreshToken": refreshToken})
+	}
+}
+
+// Refresh exchanges a refresh token for a new access token, re-reading the
+// user's roles from the database so a role change takes effect on the next
+// refresh rather than persisting for the life of the original token.
+func Refresh(db *gorm.DB, manager *auth.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			RefreshToken string `json:"refreshToken" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		claims, err := manager.VerifyRefresh(body.RefreshToken)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		var user models.User
+		if err := db.First(&user, claims.UserID).Error; err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+			return
+		}
+
+		accessToken, err := manager.IssueAccessToken(user.ID, user.Roles, accessTokenTTL)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"accessToken": accessToken})
+	}
+}
@@ -2,8 +2,10 @@ package api
 
 import (
 	"net/http"
-	"kanopt/internal/models"
+
+	"kanopt/internal/auth"
 	"kanopt/internal/messaging"
+	"kanopt/internal/models"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -13,7 +15,7 @@ import (
 func GetBoards(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var boards []models.Board
-		
+
 		result := db.Preload("Columns").Preload("Tasks").Find(&boards)
 		if result.Error != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
@@ -24,41 +26,54 @@ func GetBoards(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
-func CreateBoard(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFunc {
+func CreateBoard(db *gorm.DB, outbox *messaging.OutboxWriter) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var board models.Board
-		
+
 		if err := c.ShouldBindJSON(&board); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		// Set created by from context (would come from JWT token in real app)
-		board.CreatedBy = uuid.New()
-		board.UpdatedBy = board.CreatedBy
+		userID, _ := auth.UserID(c)
+		board.CreatedBy = userID
+		board.UpdatedBy = userID
 
-		result := db.Create(&board)
-		if result.Error != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		tx := db.Begin()
+
+		if err := tx.Create(&board).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		// The creator is automatically a member so subsequent writes
+		// (UpdateBoard, DeleteBoard, task writes) pass the membership check.
+		if err := tx.Create(&models.BoardMember{BoardID: board.ID, UserID: userID, Role: "admin"}).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		// Publish event
 		event := messaging.Event{
-			ID:        uuid.New().String(),
-			Type:      "board.created",
-			BoardID:   board.ID.String(),
-			UserID:    board.CreatedBy.String(),
+			Type:    "board.created",
+			BoardID: board.ID.String(),
+			UserID:  board.CreatedBy.String(),
 			Data: map[string]interface{}{
-				"boardId":   board.ID,
-				"name":      board.Name,
+				"boardId":     board.ID,
+				"name":        board.Name,
 				"description": board.Description,
 			},
 		}
-		
-		if err := rabbitmq.PublishEvent(event); err != nil {
-			// Log error but don't fail the request
-			c.Header("X-Event-Error", err.Error())
+		if err := outbox.Enqueue(tx, event); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
 
 		c.JSON(http.StatusCreated, board)
@@ -89,7 +104,7 @@ func GetBoard(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
-func UpdateBoard(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFunc {
+func UpdateBoard(db *gorm.DB, outbox *messaging.OutboxWriter) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 		boardID, err := uuid.Parse(id)
@@ -108,6 +123,11 @@ func UpdateBoard(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFunc {
 			return
 		}
 
+		userID, _ := auth.UserID(c)
+		if !ensureBoardMember(c, db, boardID, userID) {
+			return
+		}
+
 		var updateData models.Board
 		if err := c.ShouldBindJSON(&updateData); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -117,34 +137,42 @@ func UpdateBoard(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFunc {
 		// Update fields
 		board.Name = updateData.Name
 		board.Description = updateData.Description
+		board.UpdatedBy = userID
+
+		tx := db.Begin()
 
-		if err := db.Save(&board).Error; err != nil {
+		if err := tx.Save(&board).Error; err != nil {
+			tx.Rollback()
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		// Publish event
 		event := messaging.Event{
-			ID:      uuid.New().String(),
 			Type:    "board.updated",
 			BoardID: board.ID.String(),
-			UserID:  board.CreatedBy.String(),
+			UserID:  userID.String(),
 			Data: map[string]interface{}{
 				"boardId":     board.ID,
 				"name":        board.Name,
 				"description": board.Description,
 			},
 		}
-		
-		if err := rabbitmq.PublishEvent(event); err != nil {
-			c.Header("X-Event-Error", err.Error())
+		if err := outbox.Enqueue(tx, event); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
 
 		c.JSON(http.StatusOK, board)
 	}
 }
 
-func DeleteBoard(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFunc {
+func DeleteBoard(db *gorm.DB, outbox *messaging.OutboxWriter) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 		boardID, err := uuid.Parse(id)
@@ -163,36 +191,56 @@ func DeleteBoard(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFunc {
 			return
 		}
 
+		userID, _ := auth.UserID(c)
+		if !ensureBoardMember(c, db, boardID, userID) {
+			return
+		}
+
+		tx := db.Begin()
+
 		// Delete related records first
-		if err := db.Where("board_id = ?", boardID).Delete(&models.Task{}).Error; err != nil {
+		if err := tx.Where("board_id = ?", boardID).Delete(&models.Task{}).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := tx.Where("board_id = ?", boardID).Delete(&models.Column{}).Error; err != nil {
+			tx.Rollback()
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		if err := db.Where("board_id = ?", boardID).Delete(&models.Column{}).Error; err != nil {
+		if err := tx.Where("board_id = ?", boardID).Delete(&models.BoardMember{}).Error; err != nil {
+			tx.Rollback()
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
 		// Delete the board
-		if err := db.Delete(&board).Error; err != nil {
+		if err := tx.Delete(&board).Error; err != nil {
+			tx.Rollback()
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		// Publish event
 		event := messaging.Event{
-			ID:      uuid.New().String(),
 			Type:    "board.deleted",
 			BoardID: board.ID.String(),
-			UserID:  board.CreatedBy.String(),
+			UserID:  userID.String(),
 			Data: map[string]interface{}{
 				"boardId": board.ID,
 			},
 		}
-		
-		if err := rabbitmq.PublishEvent(event); err != nil {
-			c.Header("X-Event-Error", err.Error())
+		if err := outbox.Enqueue(tx, event); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{"message": "Board deleted successfully"})
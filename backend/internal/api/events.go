@@ -1,11 +1,14 @@
 package api
 
 import (
+	"encoding/json"
+	"fmt"
+	"kanopt/internal/messaging"
+	"kanopt/internal/models"
 	"net/http"
+	"sort"
 	"strconv"
 	"time"
-	"kanopt/internal/models"
-	"kanopt/internal/messaging"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -27,6 +30,27 @@ func GetBoardEvents(db *gorm.DB) gin.HandlerFunc {
 		offset := c.DefaultQuery("offset", "0")
 		since := c.Query("since")
 
+		// includeArchived folds in events ArchiveBoardEvents has already
+		// moved into EventArchive rollups, at the cost of pagination moving
+		// from a DB-level LIMIT/OFFSET to an in-memory merge+sort of both
+		// sources. Plain requests keep the cheap DB-paginated path below.
+		if c.Query("includeArchived") == "true" {
+			events, total, err := unionBoardEvents(db, boardID, eventType, since, parseLimit(limit), parseOffset(offset))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{
+				"events": events,
+				"pagination": gin.H{
+					"total":  total,
+					"limit":  parseLimit(limit),
+					"offset": parseOffset(offset),
+				},
+			})
+			return
+		}
+
 		query := db.Where("board_id = ?", boardID).Preload("User")
 
 		if eventType != "" {
@@ -74,6 +98,268 @@ func GetBoardEvents(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
+// GetEvent looks up a single event by ID, checking the hot Event table
+// first and falling back to scanning the board's EventArchive rollups, so
+// an event stays reachable by ID after ArchiveBoardEvents moves it out of
+// the hot table.
+func GetEvent(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		boardID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+			return
+		}
+		eventID, err := uuid.Parse(c.Param("eventId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+			return
+		}
+
+		var event models.Event
+		err = db.Where("board_id = ?", boardID).Preload("User").First(&event, eventID).Error
+		if err == nil {
+			c.JSON(http.StatusOK, event)
+			return
+		}
+		if err != gorm.ErrRecordNotFound {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		var archives []models.EventArchive
+		if err := db.Where("board_id = ?", boardID).Find(&archives).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		for _, archive := range archives {
+			for _, payload := range archive.Payloads {
+				if payload.ID != eventID {
+					continue
+				}
+				c.JSON(http.StatusOK, models.Event{
+					ID:        payload.ID,
+					BoardID:   archive.BoardID,
+					Type:      archive.Type,
+					UserID:    payload.UserID,
+					Data:      payload.Data,
+					Timestamp: payload.Timestamp,
+				})
+				return
+			}
+		}
+
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+	}
+}
+
+// ReplayBoardEvents returns every event the WAL has recorded for the board
+// since ?sinceSeq= (default 0), in sequence order, with each event's Seq
+// included so a client can pass the last one it saw as the next call's
+// sinceSeq. Unlike GetBoardEvents this reads straight from the WAL rather
+// than the database, so it also covers events appended but not yet (or
+// never) applied by EventProcessor.
+func ReplayBoardEvents(wal *messaging.WAL) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		boardID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+			return
+		}
+
+		sinceSeq, _ := strconv.ParseInt(c.DefaultQuery("sinceSeq", "0"), 10, 64)
+
+		var events []messaging.Event
+		err = wal.ReplayFrom(boardID.String(), sinceSeq, func(event messaging.Event) error {
+			events = append(events, event)
+			return nil
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"events": events, "lastSeq": wal.LastSeq(boardID.String())})
+	}
+}
+
+// ArchiveBoardEvents moves every event older than ?before= (RFC3339) from
+// the hot Event table into EventArchive rollups, one row per board+day+type,
+// merging into an existing bucket if one's already there. This keeps the
+// hot table bounded while preserving the full audit trail CreateEvent has
+// been accumulating.
+func ArchiveBoardEvents(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		boardID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+			return
+		}
+
+		beforeParam := c.Query("before")
+		if beforeParam == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "before is required (RFC3339 timestamp)"})
+			return
+		}
+		before, err := time.Parse(time.RFC3339, beforeParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid before: " + err.Error()})
+			return
+		}
+
+		var events []models.Event
+		if err := db.Where("board_id = ? AND timestamp < ?", boardID, before).
+			Order("timestamp ASC").
+			Find(&events).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if len(events) == 0 {
+			c.JSON(http.StatusOK, gin.H{"archived": 0, "buckets": 0})
+			return
+		}
+
+		type bucketKey struct {
+			day time.Time
+			typ string
+		}
+		buckets := make(map[bucketKey][]models.ArchivedEvent, len(events))
+		order := make([]bucketKey, 0, len(events))
+		for _, event := range events {
+			key := bucketKey{day: event.Timestamp.Truncate(24 * time.Hour), typ: event.Type}
+			if _, ok := buckets[key]; !ok {
+				order = append(order, key)
+			}
+			buckets[key] = append(buckets[key], models.ArchivedEvent{
+				ID:        event.ID,
+				UserID:    event.UserID,
+				Data:      event.Data,
+				Timestamp: event.Timestamp,
+			})
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			for _, key := range order {
+				payloads := buckets[key]
+
+				var archive models.EventArchive
+				err := tx.Where("board_id = ? AND day = ? AND type = ?", boardID, key.day, key.typ).
+					First(&archive).Error
+				switch {
+				case err == nil:
+					archive.Payloads = append(archive.Payloads, payloads...)
+					archive.Count += len(payloads)
+					if err := tx.Save(&archive).Error; err != nil {
+						return err
+					}
+				case err == gorm.ErrRecordNotFound:
+					if err := tx.Create(&models.EventArchive{
+						BoardID:  boardID,
+						Day:      key.day,
+						Type:     key.typ,
+						Count:    len(payloads),
+						Payloads: payloads,
+					}).Error; err != nil {
+						return err
+					}
+				default:
+					return err
+				}
+			}
+
+			ids := make([]uuid.UUID, 0, len(events))
+			for _, event := range events {
+				ids = append(ids, event.ID)
+			}
+			return tx.Where("id IN ?", ids).Delete(&models.Event{}).Error
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"archived": len(events), "buckets": len(order)})
+	}
+}
+
+// unionBoardEvents merges the hot Event table with matching EventArchive
+// rollups for GetBoardEvents' includeArchived=true option, applying the
+// same type/since filters across both sources before paginating in memory.
+func unionBoardEvents(db *gorm.DB, boardID uuid.UUID, eventType, since string, limit, offset int) ([]models.Event, int64, error) {
+	var sinceTime *time.Time
+	if since != "" {
+		if t, err := time.Parse(time.RFC3339, since); err == nil {
+			sinceTime = &t
+		}
+	}
+
+	query := db.Where("board_id = ?", boardID).Preload("User")
+	if eventType != "" {
+		query = query.Where("type = ?", eventType)
+	}
+	if sinceTime != nil {
+		query = query.Where("timestamp >= ?", *sinceTime)
+	}
+
+	var events []models.Event
+	if err := query.Find(&events).Error; err != nil {
+		return nil, 0, err
+	}
+
+	archived, err := archivedEvents(db, boardID, eventType, sinceTime)
+	if err != nil {
+		return nil, 0, err
+	}
+	events = append(events, archived...)
+
+	sort.Slice(events, func(i, j int) bool { return events[i].Timestamp.After(events[j].Timestamp) })
+
+	total := int64(len(events))
+	if offset > len(events) {
+		offset = len(events)
+	}
+	end := offset + limit
+	if end > len(events) {
+		end = len(events)
+	}
+	return events[offset:end], total, nil
+}
+
+// archivedEvents expands EventArchive rollups matching boardID/type/since
+// back into individual models.Event values.
+func archivedEvents(db *gorm.DB, boardID uuid.UUID, eventType string, since *time.Time) ([]models.Event, error) {
+	query := db.Where("board_id = ?", boardID)
+	if eventType != "" {
+		query = query.Where("type = ?", eventType)
+	}
+	if since != nil {
+		query = query.Where("day >= ?", since.Truncate(24*time.Hour))
+	}
+
+	var archives []models.EventArchive
+	if err := query.Find(&archives).Error; err != nil {
+		return nil, err
+	}
+
+	var events []models.Event
+	for _, archive := range archives {
+		for _, payload := range archive.Payloads {
+			if since != nil && payload.Timestamp.Before(*since) {
+				continue
+			}
+			events = append(events, models.Event{
+				ID:        payload.ID,
+				BoardID:   archive.BoardID,
+				Type:      archive.Type,
+				UserID:    payload.UserID,
+				Data:      payload.Data,
+				Timestamp: payload.Timestamp,
+			})
+		}
+	}
+	return events, nil
+}
+
 func CreateEvent(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var eventData struct {
@@ -122,6 +408,131 @@ func CreateEvent(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFunc {
 	}
 }
 
+// StreamBoardEvents upgrades to Server-Sent Events and pushes every new
+// event for the board in near-real time, fed by the messaging.Broadcaster's
+// RabbitMQ fanout. Reconnecting clients replay everything they missed by
+// sending either a Last-Event-ID header or a `?since=` RFC3339 timestamp
+// (the same timestamp format GetBoardEvents accepts), so no event is lost
+// across a dropped connection. The replay covers events strictly after the
+// cutoff, and a malformed since value is rejected with 400.
+func StreamBoardEvents(db *gorm.DB, broadcaster *messaging.Broadcaster) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		boardID, err := uuid.Parse(id)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+			return
+		}
+
+		eventType := c.Query("type")
+
+		since, err := resolveStreamSince(db, c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Subscribe before replaying so nothing published while we're
+		// reading the backlog from the DB can slip through the gap.
+		live, cancel := broadcaster.Subscribe(boardID)
+		defer cancel()
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+
+		flusher, ok := c.Writer.(http.Flusher)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Streaming unsupported"})
+			return
+		}
+
+		replayQuery := db.Where("board_id = ?", boardID)
+		if eventType != "" {
+			replayQuery = replayQuery.Where("type = ?", eventType)
+		}
+		if since != nil {
+			replayQuery = replayQuery.Where("timestamp > ?", *since)
+		}
+
+		var backlog []models.Event
+		if err := replayQuery.Order("timestamp ASC").Limit(500).Find(&backlog).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		for _, event := range backlog {
+			writeSSEEvent(c.Writer, event.ID.String(), event.Type, event)
+		}
+		flusher.Flush()
+
+		heartbeat := time.NewTicker(30 * time.Second)
+		defer heartbeat.Stop()
+
+		ctx := c.Request.Context()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-live:
+				if !ok {
+					return
+				}
+				if eventType != "" && event.Type != eventType {
+					continue
+				}
+				writeSSEEvent(c.Writer, event.ID, event.Type, event)
+				flusher.Flush()
+			case <-heartbeat.C:
+				c.Writer.Write([]byte(": heartbeat\n\n"))
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// resolveStreamSince figures out the replay cutoff: the Last-Event-ID
+// header (looked up against the DB for its timestamp) takes precedence
+// over an explicit `?since=` query param, matching how browsers resume an
+// EventSource after a dropped connection.
+func resolveStreamSince(db *gorm.DB, c *gin.Context) (*time.Time, error) {
+	if lastEventID := c.GetHeader("Last-Event-ID"); lastEventID != "" {
+		eventID, err := uuid.Parse(lastEventID)
+		if err != nil {
+			return nil, err
+		}
+		var last models.Event
+		if err := db.First(&last, eventID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return &last.Timestamp, nil
+	}
+
+	if since := c.Query("since"); since != "" {
+		sinceTime, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return nil, err
+		}
+		return &sinceTime, nil
+	}
+
+	return nil, nil
+}
+
+// writeSSEEvent writes one SSE frame. data is JSON-marshaled via
+// encoding/json's default handling of whatever's passed in (a
+// models.Event or a messaging.Event, both of which marshal cleanly).
+func writeSSEEvent(w http.ResponseWriter, id, eventType string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", id, eventType, payload)
+}
+
 // Helper functions for pagination
 func parseLimit(limitStr string) int {
 	if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 1000 {
@@ -136,3 +547,42 @@ func parseOffset(offsetStr string) int {
 	}
 	return 0 // Default offset
 }
+
+// ListDLQEvents returns up to ?limit= (default 50) events currently sitting
+// in the event processing dead-letter queue, for admin inspection.
+func ListDLQEvents(processor *messaging.EventProcessor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := parseLimit(c.DefaultQuery("limit", "50"))
+
+		entries, err := processor.ListDLQ(limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"events": entries})
+	}
+}
+
+// ReplayDLQEvent re-publishes the DLQ event identified by :eventId onto the
+// main event exchange for reprocessing, removing it from the DLQ.
+func ReplayDLQEvent(processor *messaging.EventProcessor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := processor.ReplayDLQEvent(c.Param("eventId")); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "replayed"})
+	}
+}
+
+// DropDLQEvent permanently discards the DLQ event identified by :eventId.
+func DropDLQEvent(processor *messaging.EventProcessor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := processor.DropDLQEvent(c.Param("eventId")); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "dropped"})
+	}
+}
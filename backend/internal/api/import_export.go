@@ -0,0 +1,357 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"kanopt/internal/auth"
+	"kanopt/internal/importers"
+	"kanopt/internal/messaging"
+	"kanopt/internal/models"
+	"kanopt/internal/ordering"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ImportBoard parses the request body with the importers.Adapter matching
+// its Content-Type (or, for application/json, the first adapter whose
+// Detect recognizes the payload's shape) and creates the resulting columns
+// and tasks under the board at :id. Column and task order from the source
+// file is preserved. Everything happens inside a single transaction, and a
+// single board.imported event carrying counts is emitted instead of one
+// per task.
+func ImportBoard(db *gorm.DB, outbox *messaging.OutboxWriter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		boardID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+			return
+		}
+
+		userID, _ := auth.UserID(c)
+		if !ensureBoardMember(c, db, boardID, userID) {
+			return
+		}
+
+		data, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		adapter, err := importers.DetectAdapter(c.ContentType(), data)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		parsed, err := adapter.Parse(bytes.NewReader(data))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var nextColumnPosition int
+		if err := db.Model(&models.Column{}).Where("board_id = ?", boardID).
+			Select("COALESCE(MAX(position), -1)").Scan(&nextColumnPosition).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		nextColumnPosition++
+
+		tx := db.Begin()
+
+		columnsImported, tasksImported, assigneesResolved := 0, 0, 0
+		for _, col := range parsed.Columns {
+			column := models.Column{BoardID: boardID, Name: col.Name, WIPLimit: col.WIPLimit, Position: nextColumnPosition}
+			if err := tx.Create(&column).Error; err != nil {
+				tx.Rollback()
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			nextColumnPosition++
+			columnsImported++
+
+			var lastPosition string
+			for _, t := range col.Tasks {
+				var assigneeID *uuid.UUID
+				if t.AssigneeEmail != "" {
+					id, resolved, err := resolveUserByEmail(tx, t.AssigneeEmail)
+					if err != nil {
+						tx.Rollback()
+						c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+						return
+					}
+					assigneeID = &id
+					if resolved {
+						assigneesResolved++
+					}
+				}
+
+				position, err := ordering.KeyBetween(lastPosition, "")
+				if err != nil {
+					tx.Rollback()
+					c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+					return
+				}
+				lastPosition = position
+
+				task := models.Task{
+					BoardID:     boardID,
+					ColumnID:    column.ID,
+					Title:       t.Title,
+					Description: t.Description,
+					Priority:    t.Priority,
+					StoryPoints: t.StoryPoints,
+					Tags:        t.Tags,
+					DueDate:     t.DueDate,
+					Position:    position,
+					AssigneeID:  assigneeID,
+					CreatedBy:   userID,
+					UpdatedBy:   userID,
+				}
+				if err := tx.Create(&task).Error; err != nil {
+					tx.Rollback()
+					c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+					return
+				}
+				tasksImported++
+			}
+		}
+
+		event := messaging.Event{
+			Type:    "board.imported",
+			BoardID: boardID.String(),
+			UserID:  userID.String(),
+			Data: map[string]interface{}{
+				"columnsImported":   columnsImported,
+				"tasksImported":     tasksImported,
+				"assigneesResolved": assigneesResolved,
+			},
+		}
+		if err := outbox.Enqueue(tx, event); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"columnsImported":   columnsImported,
+			"tasksImported":     tasksImported,
+			"assigneesResolved": assigneesResolved,
+		})
+	}
+}
+
+// resolveUserByEmail looks up a user by email, creating a minimal one if
+// none exists yet - the same upsert-on-miss behavior
+// internal/importers/jira.Importer.resolveAssignee uses for the same
+// reason (an imported task can reference a teammate who's never logged
+// into KanOpt before). resolved reports whether an existing user was
+// found, as opposed to one created here.
+func resolveUserByEmail(tx *gorm.DB, email string) (id uuid.UUID, resolved bool, err error) {
+	var user models.User
+	err = tx.Where("email = ?", email).First(&user).Error
+	if err == nil {
+		return user.ID, true, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return uuid.Nil, false, err
+	}
+
+	user = models.User{Name: email, Email: email}
+	if err := tx.Create(&user).Error; err != nil {
+		return uuid.Nil, false, err
+	}
+	return user.ID, false, nil
+}
+
+// ExportBoard streams the board's columns and tasks, in column/task
+// position order, as either native JSON (format=json, the default and the
+// format ImportBoard's NativeJSONAdapter reads back) or CSV (format=csv,
+// the format importers.CSVAdapter reads back). Tasks are streamed per
+// column straight from the database via c.Stream rather than loaded into
+// one slice, so exporting a large board doesn't hold it all in memory.
+func ExportBoard(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		boardID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+			return
+		}
+
+		var board models.Board
+		if err := db.First(&board, boardID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Board not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		var columns []models.Column
+		if err := db.Where("board_id = ?", boardID).Order("position ASC").Find(&columns).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		assigneeEmail, err := assigneeEmailsForBoard(db, boardID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if c.DefaultQuery("format", "json") == "csv" {
+			streamCSVExport(c, db, columns, assigneeEmail)
+			return
+		}
+		streamJSONExport(c, db, board, columns, assigneeEmail)
+	}
+}
+
+// assigneeEmailsForBoard returns every assignee email used on boardID,
+// keyed by user ID, in one query - cheaper than joining User on every
+// streamed task row.
+func assigneeEmailsForBoard(db *gorm.DB, boardID uuid.UUID) (map[uuid.UUID]string, error) {
+	var users []models.User
+	err := db.Where("id IN (?)", db.Model(&models.Task{}).
+		Where("board_id = ? AND assignee_id IS NOT NULL", boardID).
+		Select("DISTINCT assignee_id")).Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+
+	emails := make(map[uuid.UUID]string, len(users))
+	for _, u := range users {
+		emails[u.ID] = u.Email
+	}
+	return emails, nil
+}
+
+func streamCSVExport(c *gin.Context, db *gorm.DB, columns []models.Column, assigneeEmail map[uuid.UUID]string) {
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", `attachment; filename="board-export.csv"`)
+
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{"column", "title", "description", "priority", "storyPoints", "tags", "dueDate", "assigneeEmail"})
+	writer.Flush()
+
+	for _, column := range columns {
+		rows, err := db.Model(&models.Task{}).Where("column_id = ?", column.ID).Order("position ASC").Rows()
+		if err != nil {
+			return
+		}
+
+		c.Stream(func(w io.Writer) bool {
+			if !rows.Next() {
+				rows.Close()
+				return false
+			}
+			var task models.Task
+			if err := db.ScanRows(rows, &task); err != nil {
+				rows.Close()
+				return false
+			}
+
+			email := ""
+			if task.AssigneeID != nil {
+				email = assigneeEmail[*task.AssigneeID]
+			}
+			due := ""
+			if task.DueDate != nil {
+				due = task.DueDate.Format(time.RFC3339)
+			}
+			writer.Write([]string{
+				column.Name, task.Title, task.Description, task.Priority,
+				strconv.Itoa(task.StoryPoints), strings.Join(task.Tags, "|"), due, email,
+			})
+			writer.Flush()
+			return true
+		})
+	}
+}
+
+func streamJSONExport(c *gin.Context, db *gorm.DB, board models.Board, columns []models.Column, assigneeEmail map[uuid.UUID]string) {
+	c.Header("Content-Type", "application/json")
+	c.Header("Content-Disposition", `attachment; filename="board-export.json"`)
+
+	header, _ := json.Marshal(struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}{board.Name, board.Description})
+	c.Writer.Write(header[:len(header)-1])
+	c.Writer.Write([]byte(`,"columns":[`))
+
+	for ci, column := range columns {
+		if ci > 0 {
+			c.Writer.Write([]byte(","))
+		}
+
+		colHeader, _ := json.Marshal(struct {
+			Name     string `json:"name"`
+			WIPLimit int    `json:"wipLimit"`
+		}{column.Name, column.WIPLimit})
+		c.Writer.Write(colHeader[:len(colHeader)-1])
+		c.Writer.Write([]byte(`,"tasks":[`))
+
+		rows, err := db.Model(&models.Task{}).Where("column_id = ?", column.ID).Order("position ASC").Rows()
+		if err != nil {
+			c.Writer.Write([]byte("]}"))
+			continue
+		}
+
+		first := true
+		c.Stream(func(w io.Writer) bool {
+			if !rows.Next() {
+				rows.Close()
+				return false
+			}
+			var task models.Task
+			if err := db.ScanRows(rows, &task); err != nil {
+				rows.Close()
+				return false
+			}
+			if !first {
+				w.Write([]byte(","))
+			}
+			first = false
+
+			email := ""
+			if task.AssigneeID != nil {
+				email = assigneeEmail[*task.AssigneeID]
+			}
+			payload, err := json.Marshal(struct {
+				Title         string     `json:"title"`
+				Description   string     `json:"description"`
+				Priority      string     `json:"priority"`
+				StoryPoints   int        `json:"storyPoints"`
+				Tags          []string   `json:"tags"`
+				DueDate       *time.Time `json:"dueDate"`
+				AssigneeEmail string     `json:"assigneeEmail,omitempty"`
+			}{task.Title, task.Description, task.Priority, task.StoryPoints, task.Tags, task.DueDate, email})
+			if err != nil {
+				return true
+			}
+			w.Write(payload)
+			return true
+		})
+
+		c.Writer.Write([]byte("]}"))
+	}
+
+	c.Writer.Write([]byte("]}"))
+}
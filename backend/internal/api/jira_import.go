@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"kanopt/internal/importers/jira"
+	"kanopt/internal/messaging"
+	"kanopt/internal/models"
+)
+
+// StartJiraImport kicks off a background Jira import for the board and
+// returns the ImportJob immediately; poll GetJiraImportStatus for progress.
+func StartJiraImport(db *gorm.DB, rabbitmq *messaging.RabbitMQ, logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		boardID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+			return
+		}
+
+		var cfg jira.Config
+		if err := c.ShouldBindJSON(&cfg); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		job := models.ImportJob{
+			BoardID: boardID,
+			Source:  "jira",
+			Status:  models.ImportJobStatusPending,
+		}
+		if err := db.Create(&job).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		importer := jira.NewImporter(db, rabbitmq, logger, cfg)
+		go importer.Run(context.Background(), job.ID)
+
+		c.JSON(http.StatusAccepted, job)
+	}
+}
+
+// GetJiraImportStatus reports an import job's progress, and its final
+// counts once it has completed or failed.
+func GetJiraImportStatus(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		boardID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+			return
+		}
+		jobID, err := uuid.Parse(c.Param("jobId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+			return
+		}
+
+		var job models.ImportJob
+		err = db.Where("board_id = ?", boardID).First(&job, jobID).Error
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Import job not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, job)
+	}
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http"
+
+	"kanopt/internal/messaging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OutboxHealth reports how many events are still waiting to be published
+// from the transactional outbox and, if any are, how long the oldest has
+// been waiting. A non-zero oldestAgeSeconds that keeps growing means the
+// dispatcher isn't keeping up or RabbitMQ is unreachable.
+func OutboxHealth(dispatcher *messaging.OutboxDispatcher) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		pending, oldestAge, err := dispatcher.Stats()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"pending":          pending,
+			"oldestAgeSeconds": oldestAge.Seconds(),
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"io"
+	"net/http"
+
+	"kanopt/internal/allocator/policy"
+	"kanopt/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetAgentPolicy returns the raw policy document for :boardId, or 404 if
+// the board hasn't configured one (the allocator falls back to
+// policy.DefaultPolicy in that case).
+func GetAgentPolicy(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		boardID, err := uuid.Parse(c.Param("boardId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+			return
+		}
+
+		var p models.AgentPolicy
+		if err := db.Where("board_id = ?", boardID).First(&p).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "No policy configured for this board"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, p)
+	}
+}
+
+// PutAgentPolicy validates and upserts the policy document for :boardId.
+// The document is accepted as YAML by default; pass ?format=json to send
+// JSON instead. An invalid document (bad syntax, a rule referencing an
+// action outside allowedActionTypes, a malformed when expression, etc.)
+// is rejected with 400 rather than stored.
+func PutAgentPolicy(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		boardID, err := uuid.Parse(c.Param("boardId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+			return
+		}
+
+		format := c.DefaultQuery("format", "yaml")
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			return
+		}
+
+		parsed, err := policy.Parse(body, format)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := policy.Validate(parsed); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if _, err := policy.NewEvaluator(parsed); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var existing models.AgentPolicy
+		result := db.Where("board_id = ?", boardID).First(&existing)
+		if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+			return
+		}
+
+		existing.BoardID = boardID
+		existing.Format = format
+		existing.Document = string(body)
+
+		if err := db.Save(&existing).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, existing)
+	}
+}
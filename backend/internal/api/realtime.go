@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+
+	"kanopt/internal/auth"
+	"kanopt/internal/realtime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BoardRealtimeStream upgrades to a WebSocket and streams live events for
+// the board at the same time, gated on JWT auth and board membership. See
+// realtime.Hub.ServeWebSocket for the resume/backpressure semantics.
+func BoardRealtimeStream(db *gorm.DB, hub *realtime.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		boardID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+			return
+		}
+
+		userID, _ := auth.UserID(c)
+		if !ensureBoardMember(c, db, boardID, userID) {
+			return
+		}
+
+		hub.ServeWebSocket(c, boardID)
+	}
+}
+
+// BoardRealtimeEvents is the Server-Sent Events counterpart to
+// BoardRealtimeStream, for clients that can't or don't want to use
+// WebSockets.
+func BoardRealtimeEvents(db *gorm.DB, hub *realtime.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		boardID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+			return
+		}
+
+		userID, _ := auth.UserID(c)
+		if !ensureBoardMember(c, db, boardID, userID) {
+			return
+		}
+
+		hub.ServeSSE(c, boardID)
+	}
+}
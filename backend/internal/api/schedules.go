@@ -0,0 +1,172 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"kanopt/internal/auth"
+	"kanopt/internal/cronexpr"
+	"kanopt/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetSchedules lists ScheduledAction rows, optionally filtered to one
+// board.
+func GetSchedules(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		boardID := c.Query("boardId")
+
+		query := db.Model(&models.ScheduledAction{})
+		if boardID != "" {
+			if id, err := uuid.Parse(boardID); err == nil {
+				query = query.Where("board_id = ?", id)
+			}
+		}
+
+		var schedules []models.ScheduledAction
+		if err := query.Order("created_at DESC").Find(&schedules).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, schedules)
+	}
+}
+
+// CreateSchedule creates a recurring agent action. CronExpr is validated up
+// front (rather than left to fail silently at the next scheduler tick) and
+// NextRunAt is computed immediately so the schedule is live as soon as it's
+// created.
+func CreateSchedule(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var schedule models.ScheduledAction
+		if err := c.ShouldBindJSON(&schedule); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		userID, _ := auth.UserID(c)
+		if !ensureBoardRole(c, db, schedule.BoardID, userID, "admin") {
+			return
+		}
+
+		nextRun, err := cronexpr.NextRun(schedule.CronExpr, time.Now())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cron expression: " + err.Error()})
+			return
+		}
+
+		schedule.Enabled = true
+		schedule.NextRunAt = &nextRun
+		schedule.LastRunAt = nil
+
+		if err := db.Create(&schedule).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, schedule)
+	}
+}
+
+// updateScheduleRequest carries the fields UpdateSchedule allows changing.
+// BoardID and ActionType are fixed at creation - changing either is
+// creating a different schedule, not updating this one.
+type updateScheduleRequest struct {
+	CronExpr *string        `json:"cronExpr"`
+	Data     models.JSONMap `json:"data"`
+	Enabled  *bool          `json:"enabled"`
+}
+
+// UpdateSchedule changes a schedule's cron expression, template data, and/or
+// enabled flag, recomputing NextRunAt whenever CronExpr changes.
+func UpdateSchedule(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+			return
+		}
+
+		var schedule models.ScheduledAction
+		if err := db.First(&schedule, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		userID, _ := auth.UserID(c)
+		if !ensureBoardRole(c, db, schedule.BoardID, userID, "admin") {
+			return
+		}
+
+		var body updateScheduleRequest
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if body.CronExpr != nil {
+			nextRun, err := cronexpr.NextRun(*body.CronExpr, time.Now())
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cron expression: " + err.Error()})
+				return
+			}
+			schedule.CronExpr = *body.CronExpr
+			schedule.NextRunAt = &nextRun
+		}
+		if body.Data != nil {
+			schedule.Data = body.Data
+		}
+		if body.Enabled != nil {
+			schedule.Enabled = *body.Enabled
+		}
+
+		if err := db.Save(&schedule).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, schedule)
+	}
+}
+
+// DeleteSchedule removes a schedule; it has no effect on AgentAction rows
+// it already created.
+func DeleteSchedule(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+			return
+		}
+
+		var schedule models.ScheduledAction
+		if err := db.First(&schedule, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		userID, _ := auth.UserID(c)
+		if !ensureBoardRole(c, db, schedule.BoardID, userID, "admin") {
+			return
+		}
+
+		if err := db.Delete(&schedule).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Schedule deleted"})
+	}
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"context"
+	"sync"
+)
+
+// ExecutionTracker lets runAgentAction register an in-flight execution so a
+// graceful shutdown can wait for it to commit or roll back instead of
+// having its transaction killed mid-flight when the process exits
+// underneath it. A nil *ExecutionTracker behaves as if nothing were ever
+// draining, the same "nil means always open" convention cluster.LeaderElector
+// and messaging.Dedup use elsewhere in this codebase.
+type ExecutionTracker struct {
+	mu       sync.RWMutex
+	wg       sync.WaitGroup
+	draining bool
+}
+
+// NewExecutionTracker creates an ExecutionTracker accepting new executions.
+func NewExecutionTracker() *ExecutionTracker {
+	return &ExecutionTracker{}
+}
+
+// Start registers one in-flight execution, returning a done func the caller
+// must call when it finishes. ok is false once Drain has begun, in which
+// case the caller must not proceed with the execution.
+func (t *ExecutionTracker) Start() (done func(), ok bool) {
+	if t == nil {
+		return func() {}, true
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.draining {
+		return func() {}, false
+	}
+	t.wg.Add(1)
+	return t.wg.Done, true
+}
+
+// Drain stops accepting new executions and blocks until every
+// already-started one has finished, or until ctx is done - whichever comes
+// first.
+func (t *ExecutionTracker) Drain(ctx context.Context) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	t.draining = true
+	t.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
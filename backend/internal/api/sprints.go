@@ -0,0 +1,159 @@
+package api
+
+import (
+	"net/http"
+	"time"
+	"kanopt/internal/models"
+	"kanopt/internal/sprint"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func GetSprints(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		boardID, err := uuid.Parse(id)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+			return
+		}
+
+		var sprints []models.Sprint
+		if err := db.Where("board_id = ?", boardID).Order("start_at DESC").Find(&sprints).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, sprints)
+	}
+}
+
+func CreateSprint(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		boardID, err := uuid.Parse(id)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+			return
+		}
+
+		var newSprint models.Sprint
+		if err := c.ShouldBindJSON(&newSprint); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		newSprint.BoardID = boardID
+		if newSprint.Status == "" {
+			newSprint.Status = models.SprintStatusPlanned
+		}
+
+		if err := db.Create(&newSprint).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, newSprint)
+	}
+}
+
+func GetSprint(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sprintID, err := uuid.Parse(c.Param("sprintId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sprint ID"})
+			return
+		}
+
+		var s models.Sprint
+		if err := db.First(&s, sprintID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Sprint not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, s)
+	}
+}
+
+func UpdateSprint(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sprintID, err := uuid.Parse(c.Param("sprintId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sprint ID"})
+			return
+		}
+
+		var s models.Sprint
+		if err := db.First(&s, sprintID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Sprint not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		var updateData models.Sprint
+		if err := c.ShouldBindJSON(&updateData); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		s.Name = updateData.Name
+		s.Goal = updateData.Goal
+		s.StartAt = updateData.StartAt
+		s.EndAt = updateData.EndAt
+		s.CommittedStoryPoints = updateData.CommittedStoryPoints
+		if updateData.Status != "" {
+			s.Status = updateData.Status
+		}
+
+		if err := db.Save(&s).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, s)
+	}
+}
+
+func DeleteSprint(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sprintID, err := uuid.Parse(c.Param("sprintId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sprint ID"})
+			return
+		}
+
+		if err := db.Delete(&models.Sprint{}, sprintID).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Sprint deleted successfully"})
+	}
+}
+
+// resolveSprint finds the sprint referenced by ?sprintId=, or the board's
+// current sprint if omitted.
+func resolveSprint(db *gorm.DB, boardID uuid.UUID, sprintIDParam string) (*models.Sprint, error) {
+	if sprintIDParam != "" && sprintIDParam != "current" {
+		sprintID, err := uuid.Parse(sprintIDParam)
+		if err != nil {
+			return nil, err
+		}
+		var s models.Sprint
+		if err := db.First(&s, sprintID).Error; err != nil {
+			return nil, err
+		}
+		return &s, nil
+	}
+
+	return sprint.Current(db, boardID, time.Now())
+}
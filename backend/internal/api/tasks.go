@@ -2,16 +2,68 @@ package api
 
 import (
 	"net/http"
-	"strconv"
 	"time"
-	"kanopt/internal/models"
+
+	"kanopt/internal/auth"
 	"kanopt/internal/messaging"
+	"kanopt/internal/models"
+	"kanopt/internal/ordering"
+	"kanopt/internal/sprint"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// snapshotTaskSprint recomputes today's burndown stat for a task's sprint,
+// if any, so points/status changes show up on the chart immediately rather
+// than waiting for the daily worker. Best-effort: a snapshot error is
+// ignored and does not fail the request.
+func snapshotTaskSprint(db *gorm.DB, task models.Task) {
+	if task.SprintID == nil {
+		return
+	}
+	sprint.Snapshot(db, *task.SprintID, time.Now())
+}
+
+// lastPositionInColumn returns the highest position key currently in use in
+// columnID, or "" if the column is empty (which ordering.KeyBetween treats
+// as an unbounded lower edge).
+func lastPositionInColumn(db *gorm.DB, columnID uuid.UUID) (string, error) {
+	var last models.Task
+	err := db.Where("column_id = ?", columnID).Order("position DESC").First(&last).Error
+	if err == gorm.ErrRecordNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return last.Position, nil
+}
+
+// rebalanceColumn reissues evenly spaced, minimal-length position keys for
+// every task in columnID, ordered by their current position. It's called
+// when a key has grown past ordering.DefaultMaxKeyLength from repeated
+// inserts at the same spot (e.g. a task dragged back and forth between the
+// same two neighbors).
+func rebalanceColumn(tx *gorm.DB, columnID uuid.UUID) error {
+	var tasks []models.Task
+	if err := tx.Where("column_id = ?", columnID).Order("position ASC").Find(&tasks).Error; err != nil {
+		return err
+	}
+
+	keys := ordering.EvenlySpaced(len(tasks))
+	for i, task := range tasks {
+		if task.Position == keys[i] {
+			continue
+		}
+		if err := tx.Model(&models.Task{}).Where("id = ?", task.ID).Update("position", keys[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func GetTasks(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		boardID := c.Query("boardId")
@@ -42,35 +94,46 @@ func GetTasks(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
-func CreateTask(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFunc {
+func CreateTask(db *gorm.DB, outbox *messaging.OutboxWriter) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var task models.Task
-		
+
 		if err := c.ShouldBindJSON(&task); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		// Get the highest position in the column
-		var maxPosition int
-		db.Model(&models.Task{}).Where("column_id = ?", task.ColumnID).Select("COALESCE(MAX(position), -1)").Scan(&maxPosition)
-		task.Position = maxPosition + 1
+		userID, _ := auth.UserID(c)
+		if !ensureBoardMember(c, db, task.BoardID, userID) {
+			return
+		}
+		task.CreatedBy = userID
+		task.UpdatedBy = userID
 
-		result := db.Create(&task)
-		if result.Error != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		lastKey, err := lastPositionInColumn(db, task.ColumnID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		position, err := ordering.KeyBetween(lastKey, "")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
+		task.Position = position
 
-		// Load the task with relations
-		db.Preload("Assignee").First(&task, task.ID)
+		tx := db.Begin()
+
+		if err := tx.Create(&task).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
 
-		// Publish event
 		event := messaging.Event{
-			ID:      uuid.New().String(),
 			Type:    "task.created",
 			BoardID: task.BoardID.String(),
-			UserID:  uuid.New().String(),
+			UserID:  userID.String(),
 			Data: map[string]interface{}{
 				"taskId":      task.ID,
 				"columnId":    task.ColumnID,
@@ -81,11 +144,22 @@ func CreateTask(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFunc {
 				"position":    task.Position,
 			},
 		}
-		
-		if err := rabbitmq.PublishEvent(event); err != nil {
-			c.Header("X-Event-Error", err.Error())
+		if err := outbox.Enqueue(tx, event); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
 
+		// Load the task with relations
+		db.Preload("Assignee").First(&task, task.ID)
+
+		snapshotTaskSprint(db, task)
+
 		c.JSON(http.StatusCreated, task)
 	}
 }
@@ -114,7 +188,7 @@ func GetTask(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
-func UpdateTask(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFunc {
+func UpdateTask(db *gorm.DB, outbox *messaging.OutboxWriter) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 		taskID, err := uuid.Parse(id)
@@ -133,6 +207,11 @@ func UpdateTask(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFunc {
 			return
 		}
 
+		userID, _ := auth.UserID(c)
+		if !ensureBoardMember(c, db, task.BoardID, userID) {
+			return
+		}
+
 		var updateData models.Task
 		if err := c.ShouldBindJSON(&updateData); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -144,6 +223,7 @@ func UpdateTask(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFunc {
 		if task.CompletedAt != nil {
 			oldStatus = "completed"
 		}
+		oldSprintID := task.SprintID
 
 		// Update fields
 		task.Title = updateData.Title
@@ -153,6 +233,8 @@ func UpdateTask(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFunc {
 		task.Tags = updateData.Tags
 		task.DueDate = updateData.DueDate
 		task.AssigneeID = updateData.AssigneeID
+		task.SprintID = updateData.SprintID
+		task.UpdatedBy = userID
 
 		// Check if task is being marked as completed
 		newStatus := "active"
@@ -161,20 +243,18 @@ func UpdateTask(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFunc {
 			newStatus = "completed"
 		}
 
-		if err := db.Save(&task).Error; err != nil {
+		tx := db.Begin()
+
+		if err := tx.Save(&task).Error; err != nil {
+			tx.Rollback()
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		// Load updated task with relations
-		db.Preload("Assignee").First(&task, task.ID)
-
-		// Publish event
 		event := messaging.Event{
-			ID:      uuid.New().String(),
 			Type:    "task.updated",
 			BoardID: task.BoardID.String(),
-			UserID:  uuid.New().String(),
+			UserID:  userID.String(),
 			Data: map[string]interface{}{
 				"taskId":      task.ID,
 				"title":       task.Title,
@@ -186,16 +266,30 @@ func UpdateTask(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFunc {
 				"assigneeId":  task.AssigneeID,
 			},
 		}
-		
-		if err := rabbitmq.PublishEvent(event); err != nil {
-			c.Header("X-Event-Error", err.Error())
+		if err := outbox.Enqueue(tx, event); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
 
+		if err := tx.Commit().Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Load updated task with relations
+		db.Preload("Assignee").First(&task, task.ID)
+
+		if oldSprintID != nil && (task.SprintID == nil || *oldSprintID != *task.SprintID) {
+			sprint.Snapshot(db, *oldSprintID, time.Now())
+		}
+		snapshotTaskSprint(db, task)
+
 		c.JSON(http.StatusOK, task)
 	}
 }
 
-func DeleteTask(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFunc {
+func DeleteTask(db *gorm.DB, outbox *messaging.OutboxWriter) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 		taskID, err := uuid.Parse(id)
@@ -214,33 +308,45 @@ func DeleteTask(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFunc {
 			return
 		}
 
-		if err := db.Delete(&task).Error; err != nil {
+		userID, _ := auth.UserID(c)
+		if !ensureBoardMember(c, db, task.BoardID, userID) {
+			return
+		}
+
+		tx := db.Begin()
+
+		if err := tx.Delete(&task).Error; err != nil {
+			tx.Rollback()
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		// Publish event
 		event := messaging.Event{
-			ID:      uuid.New().String(),
 			Type:    "task.deleted",
 			BoardID: task.BoardID.String(),
-			UserID:  uuid.New().String(),
+			UserID:  userID.String(),
 			Data: map[string]interface{}{
 				"taskId":   task.ID,
 				"columnId": task.ColumnID,
 				"title":    task.Title,
 			},
 		}
-		
-		if err := rabbitmq.PublishEvent(event); err != nil {
-			c.Header("X-Event-Error", err.Error())
+		if err := outbox.Enqueue(tx, event); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{"message": "Task deleted successfully"})
 	}
 }
 
-func MoveTask(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFunc {
+func MoveTask(db *gorm.DB, outbox *messaging.OutboxWriter) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 		taskID, err := uuid.Parse(id)
@@ -250,8 +356,7 @@ func MoveTask(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFunc {
 		}
 
 		var moveData struct {
-			ColumnID    uuid.UUID `json:"columnId" binding:"required"`
-			Position    int       `json:"position" binding:"required"`
+			ColumnID     uuid.UUID  `json:"columnId" binding:"required"`
 			BeforeTaskID *uuid.UUID `json:"beforeTaskId"`
 			AfterTaskID  *uuid.UUID `json:"afterTaskId"`
 		}
@@ -271,37 +376,46 @@ func MoveTask(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFunc {
 			return
 		}
 
+		userID, _ := auth.UserID(c)
+		if !ensureBoardMember(c, db, task.BoardID, userID) {
+			return
+		}
+
 		oldColumnID := task.ColumnID
 		oldPosition := task.Position
 
-		// Start transaction
-		tx := db.Begin()
-
-		// Update positions of other tasks in the old column
-		if oldColumnID != moveData.ColumnID {
-			err = tx.Model(&models.Task{}).
-				Where("column_id = ? AND position > ?", oldColumnID, oldPosition).
-				Update("position", gorm.Expr("position - 1")).Error
+		lowerKey, err := neighborPosition(db, moveData.BeforeTaskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		upperKey, err := neighborPosition(db, moveData.AfterTaskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if lowerKey == "" && upperKey == "" {
+			// Neither neighbor was given: default to appending at the tail
+			// of the destination column.
+			lowerKey, err = lastPositionInColumn(db, moveData.ColumnID)
 			if err != nil {
-				tx.Rollback()
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 				return
 			}
 		}
-
-		// Update positions of tasks in the new column
-		err = tx.Model(&models.Task{}).
-			Where("column_id = ? AND position >= ?", moveData.ColumnID, moveData.Position).
-			Update("position", gorm.Expr("position + 1")).Error
+		newPosition, err := ordering.KeyBetween(lowerKey, upperKey)
 		if err != nil {
-			tx.Rollback()
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		// Update the task
+		// Start transaction. Unlike the old integer-position scheme, only
+		// the moved task's row is updated here - no neighbor shifting.
+		tx := db.Begin()
+
 		task.ColumnID = moveData.ColumnID
-		task.Position = moveData.Position
+		task.Position = newPosition
+		task.UpdatedBy = userID
 
 		if err := tx.Save(&task).Error; err != nil {
 			tx.Rollback()
@@ -309,32 +423,56 @@ func MoveTask(db *gorm.DB, rabbitmq *messaging.RabbitMQ) gin.HandlerFunc {
 			return
 		}
 
-		tx.Commit()
-
-		// Load updated task with relations
-		db.Preload("Assignee").First(&task, task.ID)
+		if ordering.NeedsRebalance(newPosition) {
+			if err := rebalanceColumn(tx, moveData.ColumnID); err != nil {
+				tx.Rollback()
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+		}
 
-		// Publish event
 		event := messaging.Event{
-			ID:      uuid.New().String(),
 			Type:    "task.moved",
 			BoardID: task.BoardID.String(),
-			UserID:  uuid.New().String(),
+			UserID:  userID.String(),
 			Data: map[string]interface{}{
 				"taskId":       task.ID,
 				"oldColumnId":  oldColumnID,
 				"newColumnId":  moveData.ColumnID,
 				"oldPosition":  oldPosition,
-				"newPosition":  moveData.Position,
+				"newPosition":  newPosition,
 				"beforeTaskId": moveData.BeforeTaskID,
 				"afterTaskId":  moveData.AfterTaskID,
 			},
 		}
-		
-		if err := rabbitmq.PublishEvent(event); err != nil {
-			c.Header("X-Event-Error", err.Error())
+		if err := outbox.Enqueue(tx, event); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
 
+		if err := tx.Commit().Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Load updated task with relations (a rebalance above may have
+		// changed task.Position again since it was set in memory).
+		db.Preload("Assignee").First(&task, task.ID)
+
 		c.JSON(http.StatusOK, task)
 	}
 }
+
+// neighborPosition returns the position key of the task identified by id,
+// or "" (meaning "no bound on this side") if id is nil.
+func neighborPosition(db *gorm.DB, id *uuid.UUID) (string, error) {
+	if id == nil {
+		return "", nil
+	}
+	var neighbor models.Task
+	if err := db.Select("position").First(&neighbor, *id).Error; err != nil {
+		return "", err
+	}
+	return neighbor.Position, nil
+}
@@ -0,0 +1,78 @@
+// Package audit threads a request-scoped correlation ID from the HTTP
+// layer through to RabbitMQ events and a persisted AuditLog trail, so an
+// operator can follow a single request - suggestion approval, the agent
+// action it spawned, the RabbitMQ event that carried it, the WebSocket
+// broadcast downstream of that - by one ID instead of guessing from
+// timestamps.
+package audit
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"kanopt/internal/auth"
+)
+
+const contextKey = "correlationId"
+
+// Middleware assigns each request a correlation ID, reusing the
+// X-Correlation-ID request header if the caller already set one so a
+// client-side trace ID can be threaded straight through, and stashes it on
+// the gin context for handlers, RabbitMQ events, and AuditLog rows to
+// share. It also echoes the ID back as a response header so a caller that
+// didn't set one can still look up what got assigned.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		correlationID := c.GetHeader("X-Correlation-ID")
+		if correlationID == "" {
+			correlationID = uuid.New().String()
+		}
+		c.Set(contextKey, correlationID)
+		c.Header("X-Correlation-ID", correlationID)
+		c.Next()
+	}
+}
+
+// CorrelationID returns the ID Middleware assigned to this request, or ""
+// if Middleware hasn't run (e.g. in a route group that opts out of it).
+func CorrelationID(c *gin.Context) string {
+	v, ok := c.Get(contextKey)
+	if !ok {
+		return ""
+	}
+	id, _ := v.(string)
+	return id
+}
+
+// Actor identifies who an AuditLog entry should be attributed to: the
+// authenticated user set by auth.AuthMiddleware if the route runs it, or
+// "unknown" for a route that doesn't.
+func Actor(c *gin.Context) string {
+	if userID, ok := auth.UserID(c); ok {
+		return userID.String()
+	}
+	return "unknown"
+}
+
+// RequestLogger replaces gin's default Logger() with a logrus equivalent
+// that carries each request's correlation ID, so a log line can be
+// searched for and joined against the AuditLog/event trail for the same
+// request. Install it after Middleware so CorrelationID is already set.
+func RequestLogger(logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		logger.WithFields(logrus.Fields{
+			"correlation_id": CorrelationID(c),
+			"method":         c.Request.Method,
+			"path":           c.Request.URL.Path,
+			"status":         c.Writer.Status(),
+			"latency_ms":     time.Since(start).Milliseconds(),
+			"client_ip":      c.ClientIP(),
+		}).Info("http request")
+	}
+}
@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"kanopt/internal/models"
+)
+
+// Entry is one row Record writes to the audit_logs table.
+type Entry struct {
+	CorrelationID string
+	BoardID       uuid.UUID
+	Actor         string
+	Action        string
+	ResourceType  string
+	ResourceID    string
+	Before        models.JSONMap
+	After         models.JSONMap
+	Outcome       string
+}
+
+// Record persists entry as an AuditLog row. Unlike RabbitMQ publish
+// failures elsewhere in the API (which are reported via an X-Event-Error
+// response header but don't fail the request), a Record error is returned
+// to the caller to handle explicitly - an audit trail that silently never
+// reaches the table defeats the point of having one.
+func Record(db *gorm.DB, entry Entry) error {
+	return db.Create(&models.AuditLog{
+		CorrelationID: entry.CorrelationID,
+		BoardID:       entry.BoardID,
+		Actor:         entry.Actor,
+		Action:        entry.Action,
+		ResourceType:  entry.ResourceType,
+		ResourceID:    entry.ResourceID,
+		Before:        entry.Before,
+		After:         entry.After,
+		Outcome:       entry.Outcome,
+	}).Error
+}
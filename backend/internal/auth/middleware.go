@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AuthMiddleware rejects requests without a valid access token and, for
+// ones that have one, populates the gin context with userID and roles so
+// downstream handlers and RequireRole can read them back.
+func AuthMiddleware(manager *Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := bearerToken(c.GetHeader("Authorization"))
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
+			return
+		}
+
+		claims, err := manager.VerifyAccess(token)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Set("userID", claims.UserID)
+		c.Set("roles", claims.Roles)
+		c.Next()
+	}
+}
+
+// RequireRole rejects requests whose authenticated user (set by
+// AuthMiddleware, which must run first) doesn't have role.
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, r := range Roles(c) {
+			if r == role {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "missing required role: " + role})
+	}
+}
+
+// UserID returns the authenticated user set by AuthMiddleware.
+func UserID(c *gin.Context) (uuid.UUID, bool) {
+	v, ok := c.Get("userID")
+	if !ok {
+		return uuid.UUID{}, false
+	}
+	id, ok := v.(uuid.UUID)
+	return id, ok
+}
+
+// Roles returns the authenticated user's roles set by AuthMiddleware, or
+// nil if none are set.
+func Roles(c *gin.Context) []string {
+	v, ok := c.Get("roles")
+	if !ok {
+		return nil
+	}
+	roles, _ := v.([]string)
+	return roles
+}
+
+func bearerToken(header string) string {
+	const prefix = "Bearer "
+	if !strings.HasPrefix(header, prefix) {
+		return ""
+	}
+	return strings.TrimPrefix(header, prefix)
+}
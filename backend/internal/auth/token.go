@@ -0,0 +1,125 @@
+// Package auth issues and verifies the access/refresh JWTs (RFC 7519)
+// presented to the main API. Manager signs with HS256 (a shared secret) or
+// RS256 (an RSA key pair), per config.Auth.Algorithm; RS256 lets a process
+// that only holds the public key verify tokens it could never mint.
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"kanopt/internal/config"
+)
+
+// Claims is the payload carried by both access and refresh tokens. Refresh
+// distinguishes the two so a refresh token can't be replayed as an access
+// token (or vice versa) even though they're signed with the same key.
+type Claims struct {
+	UserID  uuid.UUID `json:"userId"`
+	Roles   []string  `json:"roles"`
+	Refresh bool      `json:"refresh"`
+	jwt.RegisteredClaims
+}
+
+// Manager mints and verifies tokens for a single signing method and key.
+type Manager struct {
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// NewManager builds a Manager from cfg, parsing whatever key material
+// cfg.Algorithm requires.
+func NewManager(cfg config.Auth) (*Manager, error) {
+	switch cfg.Algorithm {
+	case "", "HS256":
+		secret := []byte(cfg.JWTSecret)
+		return &Manager{method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}, nil
+	case "RS256":
+		priv, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(cfg.JWTPrivateKeyPEM))
+		if err != nil {
+			return nil, fmt.Errorf("auth: parsing RS256 private key: %w", err)
+		}
+		verifyKey := priv.Public()
+		if cfg.JWTPublicKeyPEM != "" {
+			pub, err := jwt.ParseRSAPublicKeyFromPEM([]byte(cfg.JWTPublicKeyPEM))
+			if err != nil {
+				return nil, fmt.Errorf("auth: parsing RS256 public key: %w", err)
+			}
+			verifyKey = pub
+		}
+		return &Manager{method: jwt.SigningMethodRS256, signKey: priv, verifyKey: verifyKey}, nil
+	default:
+		return nil, fmt.Errorf("auth: unsupported algorithm %q", cfg.Algorithm)
+	}
+}
+
+// IssueAccessToken mints a short-lived token AuthMiddleware will accept.
+func (m *Manager) IssueAccessToken(userID uuid.UUID, roles []string, ttl time.Duration) (string, error) {
+	return m.issue(Claims{UserID: userID, Roles: roles, Refresh: false, RegisteredClaims: registeredClaims(ttl)})
+}
+
+// IssueRefreshToken mints a long-lived token that can only be redeemed via
+// VerifyRefresh (and, in practice, the /auth/refresh handler) to obtain a
+// new access token.
+func (m *Manager) IssueRefreshToken(userID uuid.UUID, roles []string, ttl time.Duration) (string, error) {
+	return m.issue(Claims{UserID: userID, Roles: roles, Refresh: true, RegisteredClaims: registeredClaims(ttl)})
+}
+
+func registeredClaims(ttl time.Duration) jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		ID:        uuid.New().String(),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+	}
+}
+
+func (m *Manager) issue(claims Claims) (string, error) {
+	return jwt.NewWithClaims(m.method, claims).SignedString(m.signKey)
+}
+
+// VerifyAccess verifies token and rejects it if it's expired or is actually
+// a refresh token.
+func (m *Manager) VerifyAccess(token string) (*Claims, error) {
+	claims, err := m.verify(token)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Refresh {
+		return nil, errors.New("auth: refresh token presented where an access token was expected")
+	}
+	return claims, nil
+}
+
+// VerifyRefresh verifies token and rejects it if it's expired or is
+// actually an access token.
+func (m *Manager) VerifyRefresh(token string) (*Claims, error) {
+	claims, err := m.verify(token)
+	if err != nil {
+		return nil, err
+	}
+	if !claims.Refresh {
+		return nil, errors.New("auth: access token presented where a refresh token was expected")
+	}
+	return claims, nil
+}
+
+func (m *Manager) verify(token string) (*Claims, error) {
+	var claims Claims
+	parsed, err := jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method != m.method {
+			return nil, fmt.Errorf("auth: unexpected signing method %v", t.Header["alg"])
+		}
+		return m.verifyKey, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("auth: %w", err)
+	}
+	if !parsed.Valid {
+		return nil, errors.New("auth: invalid token")
+	}
+	return &claims, nil
+}
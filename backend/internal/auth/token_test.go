@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"kanopt/internal/config"
+)
+
+func generateRSAKeyPEM(t *testing.T) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	return string(pem.EncodeToMemory(block))
+}
+
+func TestManagerIssueAndVerifyRoundTrip(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  config.Auth
+	}{
+		{"HS256", config.Auth{Algorithm: "HS256", JWTSecret: "a-very-secret-value"}},
+		{"RS256", config.Auth{Algorithm: "RS256", JWTPrivateKeyPEM: generateRSAKeyPEM(t)}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			manager, err := NewManager(c.cfg)
+			if err != nil {
+				t.Fatalf("NewManager returned error: %v", err)
+			}
+
+			userID := uuid.New()
+			token, err := manager.IssueAccessToken(userID, []string{"admin"}, time.Hour)
+			if err != nil {
+				t.Fatalf("IssueAccessToken returned error: %v", err)
+			}
+
+			claims, err := manager.VerifyAccess(token)
+			if err != nil {
+				t.Fatalf("VerifyAccess returned error: %v", err)
+			}
+			if claims.UserID != userID {
+				t.Errorf("claims.UserID = %s, want %s", claims.UserID, userID)
+			}
+			if len(claims.Roles) != 1 || claims.Roles[0] != "admin" {
+				t.Errorf("unexpected roles: %+v", claims.Roles)
+			}
+		})
+	}
+}
+
+func TestManagerRejectsRefreshTokenAsAccessToken(t *testing.T) {
+	manager, err := NewManager(config.Auth{Algorithm: "HS256", JWTSecret: "a-very-secret-value"})
+	if err != nil {
+		t.Fatalf("NewManager returned error: %v", err)
+	}
+
+	token, err := manager.IssueRefreshToken(uuid.New(), nil, time.Hour)
+	if err != nil {
+		t.Fatalf("IssueRefreshToken returned error: %v", err)
+	}
+
+	if _, err := manager.VerifyAccess(token); err == nil {
+		t.Error("VerifyAccess accepted a refresh token")
+	}
+	if _, err := manager.VerifyRefresh(token); err != nil {
+		t.Errorf("VerifyRefresh rejected a valid refresh token: %v", err)
+	}
+}
+
+func TestManagerRejectsExpiredToken(t *testing.T) {
+	manager, err := NewManager(config.Auth{Algorithm: "HS256", JWTSecret: "a-very-secret-value"})
+	if err != nil {
+		t.Fatalf("NewManager returned error: %v", err)
+	}
+
+	token, err := manager.IssueAccessToken(uuid.New(), nil, -time.Minute)
+	if err != nil {
+		t.Fatalf("IssueAccessToken returned error: %v", err)
+	}
+
+	if _, err := manager.VerifyAccess(token); err == nil {
+		t.Error("VerifyAccess accepted an expired token")
+	}
+}
+
+func TestManagerRejectsTokenFromAnotherSecret(t *testing.T) {
+	issuer, err := NewManager(config.Auth{Algorithm: "HS256", JWTSecret: "issuer-secret-value"})
+	if err != nil {
+		t.Fatalf("NewManager returned error: %v", err)
+	}
+	verifier, err := NewManager(config.Auth{Algorithm: "HS256", JWTSecret: "different-secret-value"})
+	if err != nil {
+		t.Fatalf("NewManager returned error: %v", err)
+	}
+
+	token, err := issuer.IssueAccessToken(uuid.New(), nil, time.Hour)
+	if err != nil {
+		t.Fatalf("IssueAccessToken returned error: %v", err)
+	}
+
+	if _, err := verifier.VerifyAccess(token); err == nil {
+		t.Error("VerifyAccess accepted a token signed with a different secret")
+	}
+}
+
+func TestNewManagerRejectsUnsupportedAlgorithm(t *testing.T) {
+	if _, err := NewManager(config.Auth{Algorithm: "none"}); err == nil {
+		t.Error("NewManager accepted an unsupported algorithm")
+	}
+}
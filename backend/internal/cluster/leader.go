@@ -0,0 +1,183 @@
+// Package cluster provides the primitives KanOpt needs to run safely as
+// multiple replicas: right now that's leader election, so singleton work
+// (metrics recomputation, bottleneck analysis) runs on exactly one
+// instance even though every replica consumes from the same event queue.
+package cluster
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// renewScript extends the lease's TTL only if it's still held by nodeID,
+// so a replica that was paused long enough for its lease to expire and
+// get claimed by someone else can't clobber the new leader's lease.
+const renewScript = `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+else
+	return 0
+end
+`
+
+// releaseScript deletes the lease only if it's still held by nodeID, for
+// the same reason: releasing a lease you no longer hold would steal
+// leadership out from under whoever acquired it after yours expired.
+const releaseScript = `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+else
+	return 0
+end
+`
+
+// LeaderElector holds a Redis-backed lease (SET NX PX) that at most one
+// replica can hold at a time. Callers gate singleton work behind IsLeader
+// instead of coordinating directly with Redis.
+type LeaderElector struct {
+	client *redis.Client
+	logger *logrus.Logger
+
+	key      string
+	nodeID   string
+	leaseTTL time.Duration
+
+	heartbeat time.Duration
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+
+	mu       sync.RWMutex
+	isLeader bool
+}
+
+// NewLeaderElector connects to redisURL and prepares an elector for key.
+// leaseTTL bounds how long a leader can go unresponsive before a standby
+// takes over; heartbeat (which should be well under leaseTTL, e.g. a
+// third of it) is how often the current leader renews its lease and how
+// often a standby retries acquiring it.
+func NewLeaderElector(redisURL, key string, leaseTTL, heartbeat time.Duration, logger *logrus.Logger) (*LeaderElector, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LeaderElector{
+		client:    redis.NewClient(opts),
+		logger:    logger,
+		key:       key,
+		nodeID:    uuid.New().String(),
+		leaseTTL:  leaseTTL,
+		heartbeat: heartbeat,
+		stopCh:    make(chan struct{}),
+		doneCh:    make(chan struct{}),
+	}, nil
+}
+
+// Run starts the election loop in the background. It keeps running until
+// Stop is called or ctx is canceled, at which point it releases the lease
+// (if held) so a standby can promote within one heartbeat instead of
+// waiting out the full TTL.
+func (le *LeaderElector) Run(ctx context.Context) {
+	go le.loop(ctx)
+}
+
+func (le *LeaderElector) loop(ctx context.Context) {
+	defer close(le.doneCh)
+
+	le.tryAcquireOrRenew(ctx)
+
+	ticker := time.NewTicker(le.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			le.release(context.Background())
+			return
+		case <-le.stopCh:
+			le.release(context.Background())
+			return
+		case <-ticker.C:
+			le.tryAcquireOrRenew(ctx)
+		}
+	}
+}
+
+func (le *LeaderElector) tryAcquireOrRenew(ctx context.Context) {
+	if le.IsLeader() {
+		kept, err := le.renewScriptEval(ctx)
+		if err != nil {
+			le.logger.WithError(err).Warn("Failed to renew leader lease; assuming leadership lost")
+			le.setLeader(false)
+			return
+		}
+		if !kept {
+			le.logger.Warn("Lost leader lease (renewed by someone else before we could)")
+			le.setLeader(false)
+		}
+		return
+	}
+
+	acquired, err := le.client.SetNX(ctx, le.key, le.nodeID, le.leaseTTL).Result()
+	if err != nil {
+		le.logger.WithError(err).Warn("Failed to attempt leader lease acquisition")
+		return
+	}
+	if acquired {
+		le.logger.WithField("node_id", le.nodeID).Info("Acquired leader lease")
+	}
+	le.setLeader(acquired)
+}
+
+func (le *LeaderElector) renewScriptEval(ctx context.Context) (bool, error) {
+	result, err := le.client.Eval(ctx, renewScript, []string{le.key}, le.nodeID, le.leaseTTL.Milliseconds()).Int64()
+	if err != nil {
+		return false, err
+	}
+	return result == 1, nil
+}
+
+// release drops the lease if we still hold it. Safe to call whether or
+// not we're currently the leader.
+func (le *LeaderElector) release(ctx context.Context) {
+	if !le.IsLeader() {
+		return
+	}
+	if _, err := le.client.Eval(ctx, releaseScript, []string{le.key}, le.nodeID).Result(); err != nil {
+		le.logger.WithError(err).Warn("Failed to release leader lease on shutdown")
+	}
+	le.setLeader(false)
+}
+
+// IsLeader reports whether this replica currently holds the lease.
+func (le *LeaderElector) IsLeader() bool {
+	le.mu.RLock()
+	defer le.mu.RUnlock()
+	return le.isLeader
+}
+
+func (le *LeaderElector) setLeader(v bool) {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+	le.isLeader = v
+}
+
+// NodeID identifies this replica in the election; useful for /healthz.
+func (le *LeaderElector) NodeID() string {
+	return le.nodeID
+}
+
+// Stop releases the lease (if held) and blocks until the election loop
+// has exited, so a standby can promote before this process finishes
+// shutting down.
+func (le *LeaderElector) Stop() {
+	close(le.stopCh)
+	<-le.doneCh
+	le.client.Close()
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+)
+
+// applyFlagOverrides is the final, highest-priority layer. It only
+// recognizes a handful of the most commonly-overridden-at-the-command-line
+// settings (the things an operator is likely to pass when running the
+// binary directly, as opposed to the full set env/file already cover). A
+// parse error (e.g. an unrelated flag passed through by a process
+// supervisor) is swallowed rather than fatal - this layer is a convenience
+// on top of env/file, not the primary configuration mechanism.
+func applyFlagOverrides(cfg *Config) {
+	fs := flag.NewFlagSet("kanopt", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	port := fs.String("port", cfg.HTTP.Port, "HTTP port to listen on")
+	environment := fs.String("environment", cfg.Environment, "development, staging, or production")
+	logLevel := fs.String("log-level", cfg.LogLevel, "logrus level")
+	databaseURL := fs.String("database-url", cfg.Database.URL, "Postgres connection string")
+	rabbitmqURL := fs.String("rabbitmq-url", cfg.RabbitMQ.URL, "RabbitMQ connection string")
+	redisURL := fs.String("redis-url", cfg.Redis.URL, "Redis connection string")
+
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		return
+	}
+
+	cfg.HTTP.Port = *port
+	cfg.Environment = *environment
+	cfg.LogLevel = *logLevel
+	cfg.Database.URL = *databaseURL
+	cfg.RabbitMQ.URL = *rabbitmqURL
+	cfg.Redis.URL = *redisURL
+}
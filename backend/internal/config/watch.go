@@ -0,0 +1,77 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// configFilePollInterval bounds how quickly a KANOPT_CONFIG edit is picked
+// up without an fsnotify dependency: Watch just stats the file on this
+// cadence and reloads when its mtime moves forward.
+const configFilePollInterval = 5 * time.Second
+
+// Watch reloads the configuration (re-running Load, so env/file/flags/
+// secrets are all re-resolved) whenever the process receives SIGHUP or the
+// KANOPT_CONFIG file's mtime advances, and pushes each successfully
+// reloaded snapshot to the returned channel. A reload that fails
+// validation or I/O is dropped silently - subscribers keep running on the
+// last good snapshot rather than being pushed a broken one. The channel is
+// closed once ctx is done.
+func Watch(ctx context.Context) <-chan *Config {
+	out := make(chan *Config, 1)
+
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(sighup)
+		defer close(out)
+
+		ticker := time.NewTicker(configFilePollInterval)
+		defer ticker.Stop()
+
+		lastModTime := configFileModTime()
+
+		reload := func() {
+			next, err := Load(ctx)
+			if err != nil {
+				return
+			}
+			select {
+			case out <- next:
+			case <-ctx.Done():
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sighup:
+				reload()
+			case <-ticker.C:
+				if mt := configFileModTime(); mt.After(lastModTime) {
+					lastModTime = mt
+					reload()
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+func configFileModTime() time.Time {
+	path := os.Getenv("KANOPT_CONFIG")
+	if path == "" {
+		return time.Time{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,24 @@
+// Package cronexpr is the one place standard 5-field cron expressions
+// (minute hour dom month dow, no seconds field - matching crontab(5)) get
+// parsed, so internal/api's ScheduledAction CRUD handlers and
+// internal/scheduler's poll loop agree on what's valid and compute the
+// same next-run time from the same expression.
+package cronexpr
+
+import (
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+var parser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
+// NextRun parses expr and returns the next time it fires strictly after
+// from.
+func NextRun(expr string, from time.Time) (time.Time, error) {
+	schedule, err := parser.Parse(expr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return schedule.Next(from), nil
+}
@@ -1,14 +1,27 @@
 package database
 
 import (
+	"time"
+
 	"kanopt/internal/models"
+	"kanopt/internal/ordering"
+	"kanopt/internal/querystats"
 
+	"github.com/google/uuid"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
-func Connect(databaseURL string) (*gorm.DB, error) {
+// PoolConfig mirrors config.Database's pool tunables, kept as its own type
+// here so this package doesn't have to import internal/config.
+type PoolConfig struct {
+	MaxOpenConns           int
+	MaxIdleConns           int
+	ConnMaxLifetimeMinutes int
+}
+
+func Connect(databaseURL string, pool PoolConfig) (*gorm.DB, error) {
 	db, err := gorm.Open(postgres.Open(databaseURL), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
 	})
@@ -16,19 +29,131 @@ func Connect(databaseURL string) (*gorm.DB, error) {
 		return nil, err
 	}
 
+	if err := db.Use(querystats.Plugin{}); err != nil {
+		return nil, err
+	}
+
+	if err := ApplyPoolConfig(db, pool); err != nil {
+		return nil, err
+	}
+
 	return db, nil
 }
 
+// ApplyPoolConfig (re-)applies pool to db's underlying *sql.DB. It's
+// exported separately from Connect so a config.Watch subscriber can push
+// updated pool sizes to a live connection without reopening it.
+func ApplyPoolConfig(db *gorm.DB, pool PoolConfig) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	sqlDB.SetMaxOpenConns(pool.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(pool.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(pool.ConnMaxLifetimeMinutes) * time.Minute)
+	return nil
+}
+
 func Migrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	// Task.Position changed from an integer index to a fractional/lexicographic
+	// string key (see internal/ordering). AutoMigrate below will ALTER the
+	// column's type to varchar, which would otherwise leave already-migrated
+	// rows holding stringified integers ("0", "1", "10", ...) that don't sort
+	// lexicographically in the right order. So the old integer values are read
+	// here, while the column is still an integer, and the resulting fractional
+	// keys are written back once AutoMigrate has changed the column type.
+	applyPositionBackfill, err := prepareTaskPositionBackfill(db)
+	if err != nil {
+		return err
+	}
+
+	if err := db.AutoMigrate(
 		&models.Board{},
 		&models.Column{},
 		&models.Task{},
 		&models.Event{},
+		&models.EventArchive{},
+		&models.ProcessedEvent{},
 		&models.User{},
 		&models.AgentAction{},
 		&models.Suggestion{},
 		&models.RiskPrediction{},
 		&models.VelocityMetric{},
-	)
+		&models.Sprint{},
+		&models.SprintDayStat{},
+		&models.ImportJob{},
+		&models.AgentPolicy{},
+		&models.BoardMember{},
+		&models.OutboxEvent{},
+		&models.AuditLog{},
+		&models.ScheduledAction{},
+	); err != nil {
+		return err
+	}
+
+	return applyPositionBackfill(db)
+}
+
+// prepareTaskPositionBackfill reads tasks.position while it's still an
+// integer column and computes the fractional key each row should end up
+// with, grouped and ordered by column_id. It returns a no-op function if the
+// column has already been migrated (or the table doesn't exist yet, e.g. a
+// fresh database), and otherwise a function that writes the computed keys
+// back once the caller has run AutoMigrate and the column accepts strings.
+func prepareTaskPositionBackfill(db *gorm.DB) (func(*gorm.DB) error, error) {
+	noop := func(*gorm.DB) error { return nil }
+
+	var dataType string
+	err := db.Raw(`
+		SELECT data_type FROM information_schema.columns
+		WHERE table_name = 'tasks' AND column_name = 'position'
+	`).Scan(&dataType).Error
+	if err != nil {
+		return nil, err
+	}
+	if dataType != "integer" && dataType != "bigint" && dataType != "smallint" {
+		return noop, nil
+	}
+
+	var rows []struct {
+		ID       uuid.UUID
+		ColumnID uuid.UUID
+	}
+	if err := db.Raw(`
+		SELECT id, column_id FROM tasks ORDER BY column_id, position ASC
+	`).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return noop, nil
+	}
+
+	newPosition := make(map[uuid.UUID]string, len(rows))
+	var lastKey string
+	var lastColumn uuid.UUID
+	first := true
+	for _, row := range rows {
+		if first || row.ColumnID != lastColumn {
+			lastKey = ""
+			lastColumn = row.ColumnID
+			first = false
+		}
+		key, err := ordering.KeyBetween(lastKey, "")
+		if err != nil {
+			return nil, err
+		}
+		newPosition[row.ID] = key
+		lastKey = key
+	}
+
+	return func(db *gorm.DB) error {
+		return db.Transaction(func(tx *gorm.DB) error {
+			for id, key := range newPosition {
+				if err := tx.Model(&models.Task{}).Where("id = ?", id).Update("position", key).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}, nil
 }
@@ -0,0 +1,103 @@
+package httpclient
+
+import (
+	"sync"
+	"time"
+)
+
+// Breaker states, gobreaker-style: closed lets calls through, open
+// short-circuits them, half-open lets a single probe through to decide
+// whether to close again. Mirrors internal/ai's circuitBreaker; this
+// package can't import that one (it's unexported) and outbound callers
+// outside internal/ai need the same behavior, so it's duplicated rather
+// than exported from a package whose only other client is the AI service.
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half-open"
+)
+
+// Breaker trips open after threshold consecutive failures (5xx responses
+// or request errors, as judged by the caller) and stays open for cooldown
+// before allowing a half-open probe.
+type Breaker struct {
+	mu               sync.Mutex
+	threshold        int
+	cooldown         time.Duration
+	state            string
+	consecutiveFails int
+	changedAt        time.Time
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *Breaker {
+	if threshold <= 0 {
+		threshold = 5
+	}
+	if cooldown <= 0 {
+		cooldown = 30 * time.Second
+	}
+	return &Breaker{threshold: threshold, cooldown: cooldown, state: StateClosed, changedAt: time.Now()}
+}
+
+// Allow reports whether a call should be attempted, flipping an open
+// breaker to half-open once the cooldown has elapsed.
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateClosed:
+		return true
+	case StateHalfOpen:
+		// A probe is already in flight; hold everything else back until
+		// it records its outcome.
+		return false
+	}
+	if time.Since(b.changedAt) < b.cooldown {
+		return false
+	}
+	b.setState(StateHalfOpen)
+	return true
+}
+
+func (b *Breaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.consecutiveFails = 0
+	b.setState(StateClosed)
+}
+
+func (b *Breaker) RecordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.consecutiveFails++
+	if b.state == StateHalfOpen || b.consecutiveFails >= b.threshold {
+		b.setState(StateOpen)
+	}
+}
+
+// setState is a no-op (besides bookkeeping) when state isn't actually
+// changing, so TimeInState reflects how long the breaker has truly been in
+// its current state rather than resetting on every RecordSuccess.
+func (b *Breaker) setState(state string) {
+	if b.state == state {
+		return
+	}
+	b.state = state
+	b.changedAt = time.Now()
+}
+
+func (b *Breaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// TimeInState reports how long the breaker has held its current state.
+func (b *Breaker) TimeInState() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return time.Since(b.changedAt)
+}
@@ -0,0 +1,161 @@
+// Package httpclient wraps outbound HTTP calls with a per-host token-bucket
+// rate limiter and circuit breaker, so a caller that fires many requests at
+// the same API under load (e.g. an alert storm) throttles itself instead of
+// hammering a struggling or down service. It's deliberately transport-only:
+// callers that need retries/JSON marshaling on top of this (like
+// internal/ai.Client) build that on top of Do.
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrBreakerOpen is returned instead of making a request while that host's
+// circuit breaker is open.
+var ErrBreakerOpen = errors.New("httpclient: circuit breaker open")
+
+type Config struct {
+	// RatePerSecond and Burst configure the per-host token bucket.
+	RatePerSecond float64
+	Burst         int
+
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+
+	Timeout time.Duration
+}
+
+// Client rate-limits and circuit-breaks requests per destination host,
+// reusing a single underlying *http.Client for the actual round trips.
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+// hostState is the rate limiter, breaker, and in-flight counter for one
+// destination host. Hosts are created lazily the first time Do sees them.
+type hostState struct {
+	limiter    *tokenBucket
+	breaker    *Breaker
+	queueDepth int64
+}
+
+func New(cfg Config) *Client {
+	return &Client{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+		hosts:      make(map[string]*hostState),
+	}
+}
+
+func (c *Client) hostStateFor(host string) *hostState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	hs, ok := c.hosts[host]
+	if !ok {
+		hs = &hostState{
+			limiter: newTokenBucket(c.cfg.RatePerSecond, c.cfg.Burst),
+			breaker: newBreaker(c.cfg.BreakerThreshold, c.cfg.BreakerCooldown),
+		}
+		c.hosts[host] = hs
+	}
+	return hs
+}
+
+// Do rate-limits and circuit-breaks req by its destination host, then
+// performs it. A request error or 5xx response counts as a breaker failure;
+// any other response, 4xx included, is returned to the caller without
+// tripping the breaker, since a 4xx indicates a bad request rather than a
+// struggling service.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	hs := c.hostStateFor(req.URL.Host)
+
+	atomic.AddInt64(&hs.queueDepth, 1)
+	defer atomic.AddInt64(&hs.queueDepth, -1)
+
+	if !hs.breaker.Allow() {
+		return nil, ErrBreakerOpen
+	}
+
+	if err := hs.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil || (resp != nil && resp.StatusCode >= http.StatusInternalServerError) {
+		hs.breaker.RecordFailure()
+		return resp, err
+	}
+	hs.breaker.RecordSuccess()
+	return resp, nil
+}
+
+// Get is a convenience wrapper around Do for a plain GET request.
+func (c *Client) Get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+// PostJSON is a convenience wrapper around Do for a POST with a
+// Content-Type: application/json body.
+func (c *Client) PostJSON(ctx context.Context, url string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.Do(req)
+}
+
+// HostMetrics is one destination host's current throttling state, for
+// /metrics.
+type HostMetrics struct {
+	Host            string  `json:"host"`
+	QueueDepth      int64   `json:"queueDepth"`
+	Tokens          float64 `json:"tokens"`
+	BreakerState    string  `json:"breakerState"`
+	TimeInStateSecs float64 `json:"timeInStateSeconds"`
+}
+
+// Metrics reports every host this client has made a request to, for
+// exposing queue depth/tokens/breaker state to operators.
+func (c *Client) Metrics() []HostMetrics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	metrics := make([]HostMetrics, 0, len(c.hosts))
+	for host, hs := range c.hosts {
+		metrics = append(metrics, HostMetrics{
+			Host:            host,
+			QueueDepth:      atomic.LoadInt64(&hs.queueDepth),
+			Tokens:          hs.limiter.Tokens(),
+			BreakerState:    hs.breaker.State(),
+			TimeInStateSecs: hs.breaker.TimeInState().Seconds(),
+		})
+	}
+	return metrics
+}
+
+// StatusError is returned by callers (like the allocator) that treat a
+// non-2xx response as an error after Do has already returned it.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("httpclient: unexpected status %d", e.StatusCode)
+}
@@ -0,0 +1,71 @@
+package httpclient
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// tokenBucket rations calls to ratePerSecond with bursts up to burst,
+// blocking Wait callers rather than rejecting them outright - an alert
+// storm should queue up and drain smoothly, not fail immediately.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(ratePerSecond float64, burst int) *tokenBucket {
+	if ratePerSecond <= 0 {
+		ratePerSecond = 5
+	}
+	if burst <= 0 {
+		burst = 10
+	}
+	return &tokenBucket{rate: ratePerSecond, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// Wait blocks until a token is available or ctx is canceled.
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		b.refill()
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// refill must be called with mu held.
+func (b *tokenBucket) refill() {
+	now := time.Now()
+	elapsed := now.Sub(b.last).Seconds()
+	b.last = now
+
+	b.tokens += elapsed * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
+
+// Tokens reports the current token count, for /metrics.
+func (b *tokenBucket) Tokens() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	return b.tokens
+}
@@ -0,0 +1,77 @@
+// Package idempotency caches handler responses in Redis keyed by a
+// client-supplied Idempotency-Key header, so a network retry of the same
+// logical request (e.g. a client that times out waiting on
+// ApproveSuggestion and retries the POST) replays the original response
+// instead of re-running side effects a second time.
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const keyPrefix = "kanopt:idempotency:"
+
+// CachedResponse is what Store persists for a given idempotency key: enough
+// to replay the original HTTP response byte-for-byte.
+type CachedResponse struct {
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body"`
+}
+
+// Store caches responses in Redis for ttl, keyed by an idempotency key a
+// caller supplies.
+type Store struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewStore connects to redisURL, mirroring realtime.NewRecorder/
+// cluster.NewLeaderElector's connection setup.
+func NewStore(redisURL string, ttl time.Duration) (*Store, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{client: redis.NewClient(opts), ttl: ttl}, nil
+}
+
+// Get returns the response previously cached for key, if any. key == ""
+// (no Idempotency-Key header was sent) is treated as a cache miss rather
+// than an error, since there's nothing to dedupe against.
+func (s *Store) Get(ctx context.Context, key string) (*CachedResponse, bool, error) {
+	if key == "" {
+		return nil, false, nil
+	}
+
+	raw, err := s.client.Get(ctx, keyPrefix+key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var cached CachedResponse
+	if err := json.Unmarshal(raw, &cached); err != nil {
+		return nil, false, err
+	}
+	return &cached, true, nil
+}
+
+// Put caches response for key until the Store's configured TTL elapses.
+// key == "" is a no-op.
+func (s *Store) Put(ctx context.Context, key string, response CachedResponse) error {
+	if key == "" {
+		return nil
+	}
+
+	raw, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, keyPrefix+key, raw, s.ttl).Err()
+}
@@ -0,0 +1,60 @@
+// Package importers provides pluggable parsers for bulk board import, each
+// mapping a different source format onto models.Board/Column/Task so
+// api.ImportBoard can run the result through a single transaction
+// regardless of where it came from. This is distinct from
+// internal/importers/jira, which drives a long-running, resumable pull
+// from a live Jira REST API rather than parsing a single uploaded file.
+package importers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"kanopt/internal/models"
+)
+
+var errUnrecognizedFormat = errors.New("importers: unrecognized import format")
+
+// Adapter detects and parses one board export format. Detect and Parse are
+// always called on independent readers over the same bytes (see
+// DetectAdapter), so an adapter is free to consume either fully.
+type Adapter interface {
+	// Detect reports whether r looks like this adapter's format. It must
+	// not return true for a format another adapter already claims.
+	Detect(r io.Reader) bool
+	// Parse reads r into a Board with its Columns (in source order) and
+	// each Column's Tasks (also in source order, Position/ColumnID/BoardID
+	// left unset - the caller assigns those once the real Column/Board rows
+	// exist). A Task whose assignee couldn't be resolved to a user ID yet
+	// carries the source email in Task.AssigneeEmail instead.
+	Parse(r io.Reader) (*models.Board, error)
+}
+
+// jsonAdapters is the set of Adapter implementations DetectAdapter tries
+// for an application/json payload, in priority order (most specific first,
+// since NativeJSON's Detect is the most permissive).
+var jsonAdapters = []Adapter{
+	TrelloAdapter{},
+	JiraExportAdapter{},
+	NativeJSONAdapter{},
+}
+
+// DetectAdapter picks an Adapter for data based on contentType, falling
+// back to content sniffing for application/json payloads that could be any
+// of several JSON-based formats.
+func DetectAdapter(contentType string, data []byte) (Adapter, error) {
+	switch contentType {
+	case "text/csv":
+		return CSVAdapter{}, nil
+	case "application/json", "":
+		for _, adapter := range jsonAdapters {
+			if adapter.Detect(bytes.NewReader(data)) {
+				return adapter, nil
+			}
+		}
+		return nil, errUnrecognizedFormat
+	default:
+		return nil, errUnrecognizedFormat
+	}
+}
@@ -0,0 +1,159 @@
+package importers
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("failed to read fixture %s: %v", name, err)
+	}
+	return data
+}
+
+func TestDetectAdapter(t *testing.T) {
+	cases := []struct {
+		name        string
+		fixture     string
+		contentType string
+		want        Adapter
+	}{
+		{"csv", "board.csv", "text/csv", CSVAdapter{}},
+		{"trello json", "board.trello.json", "application/json", TrelloAdapter{}},
+		{"jira export json", "board.jira.json", "application/json", JiraExportAdapter{}},
+		{"native json", "board.native.json", "application/json", NativeJSONAdapter{}},
+		{"native json with no content-type header", "board.native.json", "", NativeJSONAdapter{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data := readFixture(t, c.fixture)
+			adapter, err := DetectAdapter(c.contentType, data)
+			if err != nil {
+				t.Fatalf("DetectAdapter returned error: %v", err)
+			}
+			if reflect.TypeOf(adapter) != reflect.TypeOf(c.want) {
+				t.Errorf("DetectAdapter = %T, want %T", adapter, c.want)
+			}
+		})
+	}
+}
+
+func TestDetectAdapterUnrecognizedFormat(t *testing.T) {
+	_, err := DetectAdapter("application/xml", []byte("<board/>"))
+	if err != errUnrecognizedFormat {
+		t.Errorf("got error %v, want errUnrecognizedFormat", err)
+	}
+
+	_, err = DetectAdapter("application/json", []byte(`{"foo":"bar"}`))
+	if err != errUnrecognizedFormat {
+		t.Errorf("unrecognized JSON shape: got error %v, want errUnrecognizedFormat", err)
+	}
+}
+
+func TestCSVAdapterParse(t *testing.T) {
+	board, err := CSVAdapter{}.Parse(bytes.NewReader(readFixture(t, "board.csv")))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	if len(board.Columns) != 2 {
+		t.Fatalf("got %d columns, want 2", len(board.Columns))
+	}
+	if board.Columns[0].Name != "To Do" || len(board.Columns[0].Tasks) != 2 {
+		t.Fatalf("unexpected To Do column: %+v", board.Columns[0])
+	}
+	if board.Columns[1].Name != "In Progress" || len(board.Columns[1].Tasks) != 1 {
+		t.Fatalf("unexpected In Progress column: %+v", board.Columns[1])
+	}
+
+	first := board.Columns[0].Tasks[0]
+	if first.Title != "Set up CI" || first.Priority != "high" || first.StoryPoints != 3 {
+		t.Errorf("unexpected first task: %+v", first)
+	}
+	if len(first.Tags) != 2 || first.Tags[0] != "infra" || first.Tags[1] != "ci" {
+		t.Errorf("unexpected tags: %+v", first.Tags)
+	}
+	if first.AssigneeEmail != "alice@example.com" {
+		t.Errorf("unexpected assignee: %q", first.AssigneeEmail)
+	}
+	if first.DueDate == nil {
+		t.Error("expected due date to be parsed")
+	}
+}
+
+func TestTrelloAdapterParse(t *testing.T) {
+	board, err := TrelloAdapter{}.Parse(bytes.NewReader(readFixture(t, "board.trello.json")))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	// The "Archived" list is closed and must be dropped entirely.
+	if len(board.Columns) != 2 {
+		t.Fatalf("got %d columns, want 2 (archived list should be excluded): %+v", len(board.Columns), board.Columns)
+	}
+	if board.Columns[0].Name != "Backlog" || len(board.Columns[0].Tasks) != 1 {
+		t.Fatalf("unexpected Backlog column: %+v", board.Columns[0])
+	}
+
+	task := board.Columns[0].Tasks[0]
+	if task.Title != "Research competitors" {
+		t.Errorf("unexpected task title: %q", task.Title)
+	}
+	if task.AssigneeEmail != "dave@example.com" {
+		t.Errorf("unexpected assignee resolved from member ID: %q", task.AssigneeEmail)
+	}
+	if len(task.Tags) != 1 || task.Tags[0] != "research" {
+		t.Errorf("unexpected tags resolved from label ID: %+v", task.Tags)
+	}
+}
+
+func TestJiraExportAdapterParse(t *testing.T) {
+	board, err := JiraExportAdapter{}.Parse(bytes.NewReader(readFixture(t, "board.jira.json")))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	if len(board.Columns) != 2 {
+		t.Fatalf("got %d columns, want 2 (one per distinct status): %+v", len(board.Columns), board.Columns)
+	}
+	if board.Columns[0].Name != "To Do" || len(board.Columns[0].Tasks) != 2 {
+		t.Fatalf("unexpected To Do column: %+v", board.Columns[0])
+	}
+
+	assigned := board.Columns[0].Tasks[0]
+	if assigned.AssigneeEmail != "erin@example.com" {
+		t.Errorf("unexpected assignee: %q", assigned.AssigneeEmail)
+	}
+	unassigned := board.Columns[0].Tasks[1]
+	if unassigned.AssigneeEmail != "" {
+		t.Errorf("expected no assignee for PROJ-2, got %q", unassigned.AssigneeEmail)
+	}
+}
+
+func TestNativeJSONAdapterParse(t *testing.T) {
+	board, err := NativeJSONAdapter{}.Parse(bytes.NewReader(readFixture(t, "board.native.json")))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	if board.Name != "Launch Plan" {
+		t.Errorf("unexpected board name: %q", board.Name)
+	}
+	if len(board.Columns) != 2 {
+		t.Fatalf("got %d columns, want 2", len(board.Columns))
+	}
+	if board.Columns[0].WIPLimit != 5 {
+		t.Errorf("unexpected WIP limit: %d", board.Columns[0].WIPLimit)
+	}
+
+	task := board.Columns[0].Tasks[0]
+	if task.Title != "Draft press release" || task.StoryPoints != 2 {
+		t.Errorf("unexpected task: %+v", task)
+	}
+}
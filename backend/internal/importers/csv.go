@@ -0,0 +1,95 @@
+package importers
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"kanopt/internal/models"
+)
+
+// CSVAdapter reads one task per row. Column order (and, within a column,
+// task order) is the order each value first/next appears in the file, so a
+// spreadsheet sorted by column produces a board with columns in that order.
+//
+// Expected header: column,title,description,priority,storyPoints,tags,dueDate,assigneeEmail
+// tags is a "|"-separated list (commas are the column separator already).
+type CSVAdapter struct{}
+
+func (CSVAdapter) Detect(r io.Reader) bool {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil || len(header) < 2 {
+		return false
+	}
+	return strings.EqualFold(header[0], "column") && strings.EqualFold(header[1], "title")
+}
+
+func (CSVAdapter) Parse(r io.Reader) (*models.Board, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	field := func(row []string, name string) string {
+		i, ok := index[strings.ToLower(name)]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return row[i]
+	}
+
+	board := &models.Board{}
+	columnIndex := make(map[string]int)
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		columnName := field(row, "column")
+		ci, ok := columnIndex[columnName]
+		if !ok {
+			board.Columns = append(board.Columns, models.Column{Name: columnName})
+			ci = len(board.Columns) - 1
+			columnIndex[columnName] = ci
+		}
+
+		task := models.Task{
+			Title:         field(row, "title"),
+			Description:   field(row, "description"),
+			Priority:      field(row, "priority"),
+			AssigneeEmail: field(row, "assigneeEmail"),
+		}
+		if points := field(row, "storyPoints"); points != "" {
+			if n, err := strconv.Atoi(points); err == nil {
+				task.StoryPoints = n
+			}
+		}
+		if tags := field(row, "tags"); tags != "" {
+			task.Tags = models.StringSlice(strings.Split(tags, "|"))
+		}
+		if due := field(row, "dueDate"); due != "" {
+			if t, err := time.Parse(time.RFC3339, due); err == nil {
+				task.DueDate = &t
+			}
+		}
+
+		board.Columns[ci].Tasks = append(board.Columns[ci].Tasks, task)
+	}
+
+	return board, nil
+}
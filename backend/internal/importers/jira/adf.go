@@ -0,0 +1,32 @@
+package jira
+
+import "strings"
+
+// adfPlainText extracts a readable plain-text rendering of an Atlassian
+// Document Format node tree (Jira's rich-text format for description,
+// comments, etc.), concatenating every "text" leaf in document order and
+// inserting a newline after each paragraph.
+func adfPlainText(node interface{}) string {
+	var sb strings.Builder
+	walkADF(node, &sb)
+	return strings.TrimSpace(sb.String())
+}
+
+func walkADF(node interface{}, sb *strings.Builder) {
+	obj, ok := node.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	if text, ok := obj["text"].(string); ok {
+		sb.WriteString(text)
+	}
+
+	content, _ := obj["content"].([]interface{})
+	for _, child := range content {
+		walkADF(child, sb)
+	}
+	if typ, _ := obj["type"].(string); typ == "paragraph" {
+		sb.WriteString("\n")
+	}
+}
@@ -0,0 +1,68 @@
+package jira
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client calls the Jira Cloud REST API using basic auth with an email and
+// API token, the scheme Jira Cloud uses for personal API tokens.
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+}
+
+func NewClient(cfg Config) *Client {
+	return &Client{cfg: cfg, httpClient: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// SearchIssues calls /rest/api/3/search with the configured JQL, requesting
+// the page [startAt, startAt+maxResults).
+func (c *Client) SearchIssues(ctx context.Context, startAt, maxResults int) (*SearchResponse, error) {
+	fields := []string{"summary", "description", "assignee", "status", "duedate", "labels", "components"}
+	if c.cfg.StoryPointsField != "" {
+		fields = append(fields, c.cfg.StoryPointsField)
+	}
+	if c.cfg.SprintField != "" {
+		fields = append(fields, c.cfg.SprintField)
+	}
+
+	query := url.Values{}
+	query.Set("jql", c.cfg.JQL)
+	query.Set("startAt", fmt.Sprintf("%d", startAt))
+	query.Set("maxResults", fmt.Sprintf("%d", maxResults))
+	query.Set("fields", strings.Join(fields, ","))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.cfg.BaseURL+"/rest/api/3/search?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Basic "+basicAuth(c.cfg.Email, c.cfg.APIToken))
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jira: search returned status %d", resp.StatusCode)
+	}
+
+	var result SearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func basicAuth(email, token string) string {
+	return base64.StdEncoding.EncodeToString([]byte(email + ":" + token))
+}
@@ -0,0 +1,148 @@
+package jira
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"kanopt/internal/models"
+)
+
+func (issue Issue) summary() string {
+	s, _ := issue.Fields["summary"].(string)
+	return s
+}
+
+func (issue Issue) descriptionText() string {
+	doc, ok := issue.Fields["description"]
+	if !ok || doc == nil {
+		return ""
+	}
+	return adfPlainText(doc)
+}
+
+func (issue Issue) statusName() string {
+	status, ok := issue.Fields["status"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	name, _ := status["name"].(string)
+	return name
+}
+
+// assigneeEmail returns the issue's assignee, if any; ok is false for
+// unassigned issues.
+func (issue Issue) assigneeEmail() (email, name string, ok bool) {
+	assignee, isMap := issue.Fields["assignee"].(map[string]interface{})
+	if !isMap {
+		return "", "", false
+	}
+	email, _ = assignee["emailAddress"].(string)
+	name, _ = assignee["displayName"].(string)
+	if email == "" {
+		return "", "", false
+	}
+	return email, name, true
+}
+
+func (issue Issue) dueDate() *time.Time {
+	raw, _ := issue.Fields["duedate"].(string)
+	if raw == "" {
+		return nil
+	}
+	t, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// tags combines labels and component names into the flat tag list
+// models.Task expects.
+func (issue Issue) tags() []string {
+	var tags []string
+	if labels, ok := issue.Fields["labels"].([]interface{}); ok {
+		for _, l := range labels {
+			if s, ok := l.(string); ok {
+				tags = append(tags, s)
+			}
+		}
+	}
+	if components, ok := issue.Fields["components"].([]interface{}); ok {
+		for _, comp := range components {
+			if c, ok := comp.(map[string]interface{}); ok {
+				if name, ok := c["name"].(string); ok {
+					tags = append(tags, name)
+				}
+			}
+		}
+	}
+	return tags
+}
+
+func (issue Issue) storyPoints(field string) int {
+	if field == "" {
+		return 0
+	}
+	switch v := issue.Fields[field].(type) {
+	case float64:
+		return int(v)
+	case string:
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	return 0
+}
+
+// sprintRefs reads the structured Sprint custom field value Jira returns
+// when the field is agile-enabled: a list of objects with id/name/state/
+// startDate/endDate, rather than the legacy greenhopper string encoding.
+func (issue Issue) sprintRefs(field string) []SprintRef {
+	if field == "" {
+		return nil
+	}
+	raw, ok := issue.Fields[field].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var refs []SprintRef
+	for _, item := range raw {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		ref := SprintRef{}
+		if id, ok := obj["id"].(float64); ok {
+			ref.ID = int(id)
+		}
+		ref.Name, _ = obj["name"].(string)
+		ref.State, _ = obj["state"].(string)
+		ref.StartDate, _ = obj["startDate"].(string)
+		ref.EndDate, _ = obj["endDate"].(string)
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+func (s SprintRef) startTime() time.Time {
+	t, _ := time.Parse(time.RFC3339, s.StartDate)
+	return t
+}
+
+func (s SprintRef) endTime() time.Time {
+	t, _ := time.Parse(time.RFC3339, s.EndDate)
+	return t
+}
+
+func (s SprintRef) status() string {
+	switch strings.ToLower(s.State) {
+	case "active":
+		return models.SprintStatusActive
+	case "closed":
+		return models.SprintStatusCompleted
+	default:
+		return models.SprintStatusPlanned
+	}
+}
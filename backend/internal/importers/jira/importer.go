@@ -0,0 +1,328 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"kanopt/internal/messaging"
+	"kanopt/internal/models"
+)
+
+// pageSize is how many issues SearchIssues pulls per Jira search request.
+const pageSize = 50
+
+// systemUserEmail identifies the bot User every imported Event is
+// attributed to, upserted lazily the first time an import runs.
+const systemUserEmail = "jira-import@kanopt.local"
+
+// Importer maps Jira issues and sprints into KanOpt's Task, Sprint, and
+// VelocityMetric models, emitting one models.Event per imported task so the
+// existing event feed reflects the import the same way any other mutation
+// does.
+type Importer struct {
+	db         *gorm.DB
+	rabbitmq   *messaging.RabbitMQ
+	logger     *logrus.Logger
+	client     *Client
+	cfg        Config
+	systemUser uuid.UUID
+}
+
+func NewImporter(db *gorm.DB, rabbitmq *messaging.RabbitMQ, logger *logrus.Logger, cfg Config) *Importer {
+	return &Importer{
+		db:       db,
+		rabbitmq: rabbitmq,
+		logger:   logger,
+		client:   NewClient(cfg),
+		cfg:      cfg,
+	}
+}
+
+// Run drives one import job to completion or failure, persisting Cursor
+// after every successfully imported page so a restarted process resumes
+// from the last good page instead of starting over.
+func (imp *Importer) Run(ctx context.Context, jobID uuid.UUID) {
+	var job models.ImportJob
+	if err := imp.db.First(&job, jobID).Error; err != nil {
+		imp.logger.WithError(err).Error("jira import: job not found")
+		return
+	}
+
+	systemUser, err := imp.resolveSystemUser()
+	if err != nil {
+		imp.fail(&job, err)
+		return
+	}
+	imp.systemUser = systemUser
+
+	job.Status = models.ImportJobStatusRunning
+	if err := imp.db.Save(&job).Error; err != nil {
+		imp.logger.WithError(err).Error("jira import: failed to mark job running")
+		return
+	}
+
+	for {
+		page, err := imp.client.SearchIssues(ctx, job.Cursor, pageSize)
+		if err != nil {
+			imp.fail(&job, err)
+			return
+		}
+
+		for _, issue := range page.Issues {
+			if err := imp.importIssue(&job, issue); err != nil {
+				imp.fail(&job, err)
+				return
+			}
+		}
+
+		job.Cursor += len(page.Issues)
+		if err := imp.db.Save(&job).Error; err != nil {
+			imp.logger.WithError(err).Error("jira import: failed to persist cursor")
+			return
+		}
+
+		if len(page.Issues) == 0 || job.Cursor >= page.Total {
+			break
+		}
+	}
+
+	if err := imp.recomputeVelocityMetrics(job.BoardID); err != nil {
+		imp.logger.WithError(err).Error("jira import: velocity recompute failed")
+	}
+
+	job.Status = models.ImportJobStatusCompleted
+	if err := imp.db.Save(&job).Error; err != nil {
+		imp.logger.WithError(err).Error("jira import: failed to mark job completed")
+	}
+}
+
+func (imp *Importer) fail(job *models.ImportJob, err error) {
+	job.Status = models.ImportJobStatusFailed
+	job.Error = err.Error()
+	if saveErr := imp.db.Save(job).Error; saveErr != nil {
+		imp.logger.WithError(saveErr).Error("jira import: failed to persist failure")
+	}
+	imp.logger.WithError(err).WithField("boardId", job.BoardID).Error("jira import failed")
+}
+
+func (imp *Importer) importIssue(job *models.ImportJob, issue Issue) error {
+	sprintID, err := imp.resolveSprint(job, issue)
+	if err != nil {
+		return fmt.Errorf("resolving sprint for %s: %w", issue.Key, err)
+	}
+
+	assigneeID, err := imp.resolveAssignee(issue)
+	if err != nil {
+		return fmt.Errorf("resolving assignee for %s: %w", issue.Key, err)
+	}
+
+	columnID, err := imp.resolveColumn(job.BoardID, issue.statusName())
+	if err != nil {
+		return fmt.Errorf("resolving column for %s: %w", issue.Key, err)
+	}
+
+	task := models.Task{
+		BoardID:     job.BoardID,
+		ColumnID:    columnID,
+		Title:       issue.summary(),
+		Description: issue.descriptionText(),
+		AssigneeID:  assigneeID,
+		SprintID:    sprintID,
+		StoryPoints: issue.storyPoints(imp.cfg.StoryPointsField),
+		Tags:        issue.tags(),
+		DueDate:     issue.dueDate(),
+	}
+	if err := imp.db.Create(&task).Error; err != nil {
+		return fmt.Errorf("creating task for %s: %w", issue.Key, err)
+	}
+	job.TasksImported++
+
+	event := models.Event{
+		BoardID: job.BoardID,
+		Type:    "task.imported",
+		UserID:  imp.systemUser,
+		Data: models.JSONMap{
+			"jiraKey": issue.Key,
+			"taskId":  task.ID.String(),
+		},
+		Timestamp: time.Now(),
+	}
+	if err := imp.db.Create(&event).Error; err != nil {
+		return fmt.Errorf("recording import event for %s: %w", issue.Key, err)
+	}
+	job.EventsEmitted++
+
+	if imp.rabbitmq != nil {
+		if err := imp.rabbitmq.PublishEvent(messaging.Event{
+			ID:        event.ID.String(),
+			Type:      event.Type,
+			BoardID:   event.BoardID.String(),
+			UserID:    event.UserID.String(),
+			Data:      event.Data,
+			Timestamp: event.Timestamp,
+		}); err != nil {
+			imp.logger.WithError(err).Warn("jira import: failed to publish event")
+		}
+	}
+
+	return nil
+}
+
+func (imp *Importer) resolveSystemUser() (uuid.UUID, error) {
+	var user models.User
+	err := imp.db.Where("email = ?", systemUserEmail).First(&user).Error
+	if err == nil {
+		return user.ID, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return uuid.Nil, err
+	}
+
+	user = models.User{Name: "Jira Import", Email: systemUserEmail}
+	if err := imp.db.Create(&user).Error; err != nil {
+		return uuid.Nil, err
+	}
+	return user.ID, nil
+}
+
+func (imp *Importer) resolveAssignee(issue Issue) (*uuid.UUID, error) {
+	email, name, ok := issue.assigneeEmail()
+	if !ok {
+		return nil, nil
+	}
+
+	var user models.User
+	err := imp.db.Where("email = ?", email).First(&user).Error
+	if err == nil {
+		return &user.ID, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	user = models.User{Name: name, Email: email}
+	if err := imp.db.Create(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user.ID, nil
+}
+
+// resolveColumn maps a Jira status name to a board column via
+// Config.StatusColumnMap, falling back to the board's first column (lowest
+// Position) for statuses that aren't mapped.
+func (imp *Importer) resolveColumn(boardID uuid.UUID, statusName string) (uuid.UUID, error) {
+	var column models.Column
+	err := gorm.ErrRecordNotFound
+	if columnName, mapped := imp.cfg.StatusColumnMap[statusName]; mapped {
+		err = imp.db.Where("board_id = ? AND name = ?", boardID, columnName).First(&column).Error
+	}
+	if err == gorm.ErrRecordNotFound {
+		err = imp.db.Where("board_id = ?", boardID).Order("position ASC").First(&column).Error
+	}
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return column.ID, nil
+}
+
+// resolveSprint upserts the Sprint the issue's Sprint custom field points
+// to, matching existing board sprints by name since Jira sprint names are
+// unique within a project. Issues that moved across multiple sprints carry
+// a history in the field; the last entry is the issue's current sprint.
+// Each newly created Sprint is counted in job.SprintsImported.
+func (imp *Importer) resolveSprint(job *models.ImportJob, issue Issue) (*uuid.UUID, error) {
+	boardID := job.BoardID
+	refs := issue.sprintRefs(imp.cfg.SprintField)
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	ref := refs[len(refs)-1]
+
+	var sprint models.Sprint
+	err := imp.db.Where("board_id = ? AND name = ?", boardID, ref.Name).First(&sprint).Error
+	if err == nil {
+		return &sprint.ID, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	sprint = models.Sprint{
+		BoardID: boardID,
+		Name:    ref.Name,
+		StartAt: ref.startTime(),
+		EndAt:   ref.endTime(),
+		Status:  ref.status(),
+	}
+	if err := imp.db.Create(&sprint).Error; err != nil {
+		return nil, err
+	}
+	job.SprintsImported++
+	return &sprint.ID, nil
+}
+
+// recomputeVelocityMetrics rebuilds one VelocityMetric per completed sprint
+// on the board from the tasks the import just attached to it, the same
+// completed-points-over-sprint-weeks calculation GetVelocityMetrics expects
+// to find already computed.
+func (imp *Importer) recomputeVelocityMetrics(boardID uuid.UUID) error {
+	var sprints []models.Sprint
+	if err := imp.db.Where("board_id = ? AND status = ?", boardID, models.SprintStatusCompleted).
+		Find(&sprints).Error; err != nil {
+		return err
+	}
+
+	for _, s := range sprints {
+		var tasks []models.Task
+		if err := imp.db.Where("sprint_id = ?", s.ID).Find(&tasks).Error; err != nil {
+			return err
+		}
+
+		completedPoints, totalPoints, throughput := 0, 0, 0
+		for _, t := range tasks {
+			totalPoints += t.StoryPoints
+			if t.CompletedAt != nil {
+				completedPoints += t.StoryPoints
+				throughput++
+			}
+		}
+
+		velocity := float64(completedPoints)
+		if weeks := s.EndAt.Sub(s.StartAt).Hours() / (24 * 7); weeks > 0 {
+			velocity = float64(completedPoints) / weeks
+		}
+
+		sprintID := s.ID
+		var metric models.VelocityMetric
+		err := imp.db.Where("board_id = ? AND sprint_id = ?", boardID, s.ID).First(&metric).Error
+		switch {
+		case err == nil:
+			metric.Velocity = velocity
+			metric.Completed = completedPoints
+			metric.TotalPoints = totalPoints
+			metric.Throughput = throughput
+			if err := imp.db.Save(&metric).Error; err != nil {
+				return err
+			}
+		case err == gorm.ErrRecordNotFound:
+			if err := imp.db.Create(&models.VelocityMetric{
+				BoardID:     boardID,
+				SprintID:    &sprintID,
+				Velocity:    velocity,
+				Completed:   completedPoints,
+				TotalPoints: totalPoints,
+				Throughput:  throughput,
+			}).Error; err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+	}
+	return nil
+}
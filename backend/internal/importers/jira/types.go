@@ -0,0 +1,49 @@
+// Package jira imports Jira Cloud issues into a KanOpt board: tasks,
+// sprints, and the velocity metrics computed from them, following the same
+// Issue/Sprint -> task/sprint shape devlake's Jira plugin maps onto its own
+// domain layer.
+package jira
+
+// Config configures one import run: how to reach Jira, which issues to
+// pull, and how Jira's fields map onto our models. StoryPointsField and
+// SprintField are the custom field IDs Jira assigns those fields per
+// instance (e.g. "customfield_10016"), since they aren't part of the fixed
+// issue schema.
+type Config struct {
+	BaseURL          string            `json:"baseUrl" binding:"required"`
+	Email            string            `json:"email" binding:"required"`
+	APIToken         string            `json:"apiToken" binding:"required"`
+	JQL              string            `json:"jql" binding:"required"`
+	StoryPointsField string            `json:"storyPointsField"`
+	SprintField      string            `json:"sprintField"`
+	StatusColumnMap  map[string]string `json:"statusColumnMap"` // Jira status name -> board column name
+}
+
+// SearchResponse is the /rest/api/3/search response shape.
+type SearchResponse struct {
+	StartAt    int     `json:"startAt"`
+	MaxResults int     `json:"maxResults"`
+	Total      int     `json:"total"`
+	Issues     []Issue `json:"issues"`
+}
+
+// Issue is a Jira issue as returned by /rest/api/3/search. Fields is left
+// as a raw map since the custom fields carrying story points and sprint
+// assignment have project-specific keys (see Config.StoryPointsField /
+// SprintField); see fields.go for typed accessors into it.
+type Issue struct {
+	ID     string                 `json:"id"`
+	Key    string                 `json:"key"`
+	Fields map[string]interface{} `json:"fields"`
+}
+
+// SprintRef is one entry of the Sprint custom field, in the structured
+// object form Jira returns when the field is agile-enabled, rather than
+// the legacy greenhopper string encoding.
+type SprintRef struct {
+	ID        int
+	Name      string
+	State     string // active, closed, future
+	StartDate string
+	EndDate   string
+}
@@ -0,0 +1,89 @@
+package importers
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"kanopt/internal/models"
+)
+
+// JiraExportAdapter reads a static Jira issue-export JSON file (Issues ->
+// Export -> Export JSON, or the body of a `jira-rest-client` search result
+// saved to disk). This is a one-shot parse of an uploaded file, unlike
+// internal/importers/jira, which pulls issues live from a Jira REST API
+// across a resumable, paginated job.
+type JiraExportAdapter struct{}
+
+type jiraExportFile struct {
+	Issues []jiraExportIssue `json:"issues"`
+}
+
+type jiraExportIssue struct {
+	Key    string          `json:"key"`
+	Fields jiraExportField `json:"fields"`
+}
+
+type jiraExportField struct {
+	Summary     string           `json:"summary"`
+	Description string           `json:"description"`
+	Status      jiraExportStatus `json:"status"`
+	Assignee    *jiraExportUser  `json:"assignee"`
+	Labels      []string         `json:"labels"`
+	DueDate     *time.Time       `json:"duedate"`
+}
+
+type jiraExportStatus struct {
+	Name string `json:"name"`
+}
+
+type jiraExportUser struct {
+	DisplayName  string `json:"displayName"`
+	EmailAddress string `json:"emailAddress"`
+}
+
+func (JiraExportAdapter) Detect(r io.Reader) bool {
+	var file jiraExportFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return false
+	}
+	if len(file.Issues) == 0 {
+		return false
+	}
+	return file.Issues[0].Key != "" || file.Issues[0].Fields.Summary != ""
+}
+
+func (JiraExportAdapter) Parse(r io.Reader) (*models.Board, error) {
+	var file jiraExportFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, err
+	}
+
+	board := &models.Board{}
+	columnByName := make(map[string]int)
+
+	for _, issue := range file.Issues {
+		statusName := issue.Fields.Status.Name
+		ci, ok := columnByName[statusName]
+		if !ok {
+			board.Columns = append(board.Columns, models.Column{Name: statusName})
+			ci = len(board.Columns) - 1
+			columnByName[statusName] = ci
+		}
+
+		var assigneeEmail string
+		if issue.Fields.Assignee != nil {
+			assigneeEmail = issue.Fields.Assignee.EmailAddress
+		}
+
+		board.Columns[ci].Tasks = append(board.Columns[ci].Tasks, models.Task{
+			Title:         issue.Fields.Summary,
+			Description:   issue.Fields.Description,
+			Tags:          models.StringSlice(issue.Fields.Labels),
+			DueDate:       issue.Fields.DueDate,
+			AssigneeEmail: assigneeEmail,
+		})
+	}
+
+	return board, nil
+}
@@ -0,0 +1,70 @@
+package importers
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"kanopt/internal/models"
+)
+
+// NativeJSONAdapter reads the same nested shape ExportBoard writes for
+// format=json: a board with its columns, each carrying its own tasks in
+// order. It's the catch-all JSON adapter, tried last since any JSON object
+// with a "columns" array would otherwise also satisfy it.
+type NativeJSONAdapter struct{}
+
+type nativeBoard struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Columns     []nativeColumn `json:"columns"`
+}
+
+type nativeColumn struct {
+	Name     string       `json:"name"`
+	WIPLimit int          `json:"wipLimit"`
+	Tasks    []nativeTask `json:"tasks"`
+}
+
+type nativeTask struct {
+	Title         string     `json:"title"`
+	Description   string     `json:"description"`
+	Priority      string     `json:"priority"`
+	StoryPoints   int        `json:"storyPoints"`
+	Tags          []string   `json:"tags"`
+	DueDate       *time.Time `json:"dueDate"`
+	AssigneeEmail string     `json:"assigneeEmail"`
+}
+
+func (NativeJSONAdapter) Detect(r io.Reader) bool {
+	var board nativeBoard
+	if err := json.NewDecoder(r).Decode(&board); err != nil {
+		return false
+	}
+	return board.Columns != nil
+}
+
+func (NativeJSONAdapter) Parse(r io.Reader) (*models.Board, error) {
+	var parsed nativeBoard
+	if err := json.NewDecoder(r).Decode(&parsed); err != nil {
+		return nil, err
+	}
+
+	board := &models.Board{Name: parsed.Name, Description: parsed.Description}
+	for _, col := range parsed.Columns {
+		column := models.Column{Name: col.Name, WIPLimit: col.WIPLimit}
+		for _, t := range col.Tasks {
+			column.Tasks = append(column.Tasks, models.Task{
+				Title:         t.Title,
+				Description:   t.Description,
+				Priority:      t.Priority,
+				StoryPoints:   t.StoryPoints,
+				Tags:          models.StringSlice(t.Tags),
+				DueDate:       t.DueDate,
+				AssigneeEmail: t.AssigneeEmail,
+			})
+		}
+		board.Columns = append(board.Columns, column)
+	}
+	return board, nil
+}
@@ -0,0 +1,119 @@
+package importers
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"kanopt/internal/models"
+)
+
+// TrelloAdapter reads a Trello board export (Menu -> More -> Print and
+// Export -> Export as JSON).
+type TrelloAdapter struct{}
+
+type trelloBoard struct {
+	Name    string        `json:"name"`
+	Lists   []trelloList  `json:"lists"`
+	Cards   []trelloCard  `json:"cards"`
+	Members []trelloUser  `json:"members"`
+	Labels  []trelloLabel `json:"labels"`
+}
+
+type trelloList struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Closed bool    `json:"closed"`
+	Pos    float64 `json:"pos"`
+}
+
+type trelloCard struct {
+	IDList    string     `json:"idList"`
+	Name      string     `json:"name"`
+	Desc      string     `json:"desc"`
+	Pos       float64    `json:"pos"`
+	Due       *time.Time `json:"due"`
+	IDMembers []string   `json:"idMembers"`
+	IDLabels  []string   `json:"idLabels"`
+	Closed    bool       `json:"closed"`
+}
+
+type trelloUser struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	FullName string `json:"fullName"`
+	Email    string `json:"email"`
+}
+
+type trelloLabel struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func (TrelloAdapter) Detect(r io.Reader) bool {
+	var board trelloBoard
+	if err := json.NewDecoder(r).Decode(&board); err != nil {
+		return false
+	}
+	return board.Lists != nil && board.Cards != nil
+}
+
+func (TrelloAdapter) Parse(r io.Reader) (*models.Board, error) {
+	var parsed trelloBoard
+	if err := json.NewDecoder(r).Decode(&parsed); err != nil {
+		return nil, err
+	}
+
+	membersByID := make(map[string]trelloUser, len(parsed.Members))
+	for _, m := range parsed.Members {
+		membersByID[m.ID] = m
+	}
+	labelsByID := make(map[string]string, len(parsed.Labels))
+	for _, l := range parsed.Labels {
+		labelsByID[l.ID] = l.Name
+	}
+
+	columnByListID := make(map[string]int, len(parsed.Lists))
+	board := &models.Board{Name: parsed.Name}
+	for _, list := range parsed.Lists {
+		if list.Closed {
+			continue
+		}
+		board.Columns = append(board.Columns, models.Column{Name: list.Name})
+		columnByListID[list.ID] = len(board.Columns) - 1
+	}
+
+	for _, card := range parsed.Cards {
+		if card.Closed {
+			continue
+		}
+		ci, ok := columnByListID[card.IDList]
+		if !ok {
+			continue
+		}
+
+		var tags models.StringSlice
+		for _, labelID := range card.IDLabels {
+			if name, ok := labelsByID[labelID]; ok && name != "" {
+				tags = append(tags, name)
+			}
+		}
+
+		var assigneeEmail string
+		if len(card.IDMembers) > 0 {
+			if member, ok := membersByID[card.IDMembers[0]]; ok {
+				assigneeEmail = member.Email
+			}
+		}
+
+		board.Columns[ci].Tasks = append(board.Columns[ci].Tasks, models.Task{
+			Title:         card.Name,
+			Description:   card.Desc,
+			Tags:          tags,
+			DueDate:       card.Due,
+			AssigneeEmail: assigneeEmail,
+		})
+	}
+
+	return board, nil
+}
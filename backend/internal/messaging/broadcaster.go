@@ -0,0 +1,95 @@
+package messaging
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Broadcaster fans out events consumed from RabbitMQ to per-board
+// subscriber channels, so HTTP handlers (SSE, WebSocket) can push updates
+// to connected clients without touching the broker directly.
+type Broadcaster struct {
+	mu          sync.RWMutex
+	subscribers map[uuid.UUID][]chan Event
+	logger      *logrus.Logger
+}
+
+// NewBroadcaster starts consuming the event exchange on its own fanout
+// queue and returns a Broadcaster that subscribers can register against.
+func NewBroadcaster(rabbitmq *RabbitMQ, logger *logrus.Logger) (*Broadcaster, error) {
+	b := newBroadcaster(logger)
+
+	if err := rabbitmq.ConsumeFanout(b.Dispatch); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// NewStandaloneBroadcaster returns a Broadcaster with no RabbitMQ consumer
+// attached, for tests that want to call Dispatch directly against a
+// synthetic event instead of running a broker.
+func NewStandaloneBroadcaster(logger *logrus.Logger) *Broadcaster {
+	return newBroadcaster(logger)
+}
+
+func newBroadcaster(logger *logrus.Logger) *Broadcaster {
+	return &Broadcaster{
+		subscribers: make(map[uuid.UUID][]chan Event),
+		logger:      logger,
+	}
+}
+
+// Dispatch fans event out to boardID's subscribers. It's the callback
+// ConsumeFanout invokes for events arriving off RabbitMQ; it's exported so
+// tests can publish a synthetic event without standing up a broker.
+func (b *Broadcaster) Dispatch(event Event) {
+	boardID, err := uuid.Parse(event.BoardID)
+	if err != nil {
+		b.logger.WithError(err).WithField("event_id", event.ID).Warn("Broadcaster: event has no valid board ID")
+		return
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, ch := range b.subscribers[boardID] {
+		select {
+		case ch <- event:
+		default:
+			b.logger.WithField("board_id", boardID).Warn("Broadcaster: subscriber channel full, dropping event")
+		}
+	}
+}
+
+// Subscribe registers a new buffered channel for boardID's events. The
+// returned cancel func must be called when the subscriber is done (e.g. on
+// request context cancellation) to unregister and release the channel.
+func (b *Broadcaster) Subscribe(boardID uuid.UUID) (<-chan Event, func()) {
+	ch := make(chan Event, 16)
+
+	b.mu.Lock()
+	b.subscribers[boardID] = append(b.subscribers[boardID], ch)
+	b.mu.Unlock()
+
+	cancel := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		subs := b.subscribers[boardID]
+		for i, c := range subs {
+			if c == ch {
+				b.subscribers[boardID] = append(subs[:i], subs[i+1:]...)
+				break
+			}
+		}
+		if len(b.subscribers[boardID]) == 0 {
+			delete(b.subscribers, boardID)
+		}
+		close(ch)
+	}
+
+	return ch, cancel
+}
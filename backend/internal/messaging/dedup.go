@@ -0,0 +1,120 @@
+package messaging
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bits-and-blooms/bloom/v3"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"kanopt/internal/models"
+)
+
+// dedupEstimatedEvents and dedupFalsePositiveRate size each bloom filter
+// generation Dedup keeps: an estimated steady-state event volume per
+// rotation window and the false-positive rate we're willing to pay in
+// extra (harmless) authoritative-store lookups.
+const (
+	dedupEstimatedEvents   = 100000
+	dedupFalsePositiveRate = 0.001
+)
+
+// Dedup rejects events EventProcessor has already handled, so a RabbitMQ
+// redelivery (e.g. after a Nack-with-requeue) doesn't double-apply side
+// effects like velocity updates or duplicate RiskPrediction rows.
+//
+// A bloom filter answers "definitely new" vs. "maybe seen" in O(1)
+// without a DB round trip. "Maybe seen" falls through to the
+// authoritative models.ProcessedEvent table, which also covers the bloom
+// filter's unavoidable false-positive path.
+//
+// Two filter generations are kept side by side and rotated on
+// rotateEvery so memory stays bounded however long the process runs:
+// writes always go to current, reads check both, and rotating retires
+// the older generation instead of letting one filter grow forever.
+type Dedup struct {
+	db          *gorm.DB
+	rotateEvery time.Duration
+
+	mu        sync.Mutex
+	current   *bloom.BloomFilter
+	previous  *bloom.BloomFilter
+	rotatedAt time.Time
+}
+
+// NewDedup creates a Dedup backed by db's processed_events table.
+// rotateEvery bounds how long a bloom filter generation lives before
+// being retired; pass 0 to disable rotation (the filter then just grows
+// until restart).
+func NewDedup(db *gorm.DB, rotateEvery time.Duration) *Dedup {
+	return &Dedup{
+		db:          db,
+		rotateEvery: rotateEvery,
+		current:     bloom.NewWithEstimates(dedupEstimatedEvents, dedupFalsePositiveRate),
+		rotatedAt:   time.Now(),
+	}
+}
+
+// Seen reports whether eventID has already been marked processed via
+// MarkProcessed. It only ever consults the authoritative store when the
+// bloom filter can't rule the ID out on its own, so most calls are O(1).
+func (d *Dedup) Seen(eventID string) (bool, error) {
+	d.mu.Lock()
+	d.rotateIfDue()
+	maybeSeen := d.current.TestString(eventID) || (d.previous != nil && d.previous.TestString(eventID))
+	d.mu.Unlock()
+
+	if !maybeSeen {
+		return false, nil
+	}
+	return d.AlreadyProcessed(eventID)
+}
+
+// AlreadyProcessed checks the authoritative store directly, bypassing the
+// bloom filter entirely. Dedup.Seen uses this for its "maybe seen"
+// fallback; callers that can't trust the in-memory filter's state (e.g.
+// boot-time WAL reconciliation, where a freshly started process's filter
+// is empty regardless of what was processed before the restart) should
+// call it directly instead of Seen.
+func (d *Dedup) AlreadyProcessed(eventID string) (bool, error) {
+	id, err := uuid.Parse(eventID)
+	if err != nil {
+		return false, nil
+	}
+
+	var count int64
+	if err := d.db.Model(&models.ProcessedEvent{}).Where("event_id = ?", id).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// MarkProcessed records eventID as processed: in the current bloom
+// generation immediately, and in the authoritative store durably.
+// Callers should only call this once an event has actually finished
+// processing, so a handler error followed by a legitimate redelivery
+// still gets a chance to run.
+func (d *Dedup) MarkProcessed(eventID string) error {
+	d.mu.Lock()
+	d.current.AddString(eventID)
+	d.mu.Unlock()
+
+	id, err := uuid.Parse(eventID)
+	if err != nil {
+		return nil
+	}
+
+	return d.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.ProcessedEvent{EventID: id}).Error
+}
+
+func (d *Dedup) rotateIfDue() {
+	if d.rotateEvery <= 0 || time.Since(d.rotatedAt) < d.rotateEvery {
+		return
+	}
+	d.previous = d.current
+	d.current = bloom.NewWithEstimates(dedupEstimatedEvents, dedupFalsePositiveRate)
+	d.rotatedAt = time.Now()
+}
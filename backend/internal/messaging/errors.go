@@ -0,0 +1,31 @@
+package messaging
+
+import "errors"
+
+// PermanentError marks a handler failure that a retry cannot fix — a
+// malformed payload, an unparseable ID, anything that will fail the exact
+// same way on redelivery. ConsumeEvents routes these straight to the DLQ
+// instead of burning retry attempts on them.
+type PermanentError struct {
+	Err error
+}
+
+// NewPermanentError wraps err so ConsumeEvents treats it as permanent.
+func NewPermanentError(err error) *PermanentError {
+	return &PermanentError{Err: err}
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// IsPermanent reports whether err (or anything it wraps) is a
+// *PermanentError.
+func IsPermanent(err error) bool {
+	var permanent *PermanentError
+	return errors.As(err, &permanent)
+}
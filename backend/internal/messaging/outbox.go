@@ -0,0 +1,175 @@
+package messaging
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"kanopt/internal/models"
+)
+
+// OutboxWriter inserts an Event as a models.OutboxEvent row on the caller's
+// transaction, so a handler can make its Postgres mutation and its event
+// durable in one commit instead of calling RabbitMQ.PublishEvent directly
+// and risking a committed mutation with no event ever published.
+type OutboxWriter struct{}
+
+func NewOutboxWriter() *OutboxWriter {
+	return &OutboxWriter{}
+}
+
+// Enqueue writes event to the outbox on tx. event.ID, event.BoardID, and
+// event.UserID are parsed as UUIDs (stamping a fresh event.ID if it's
+// empty), since the outbox table stores them typed for indexing.
+func (w *OutboxWriter) Enqueue(tx *gorm.DB, event Event) error {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	messageID, err := uuid.Parse(event.ID)
+	if err != nil {
+		return err
+	}
+	boardID, err := uuid.Parse(event.BoardID)
+	if err != nil {
+		return err
+	}
+	userID, err := uuid.Parse(event.UserID)
+	if err != nil {
+		return err
+	}
+
+	row := models.OutboxEvent{
+		MessageID:     messageID,
+		BoardID:       boardID,
+		Type:          event.Type,
+		UserID:        userID,
+		Data:          models.JSONMap(event.Data),
+		NextAttemptAt: time.Now(),
+	}
+	return tx.Create(&row).Error
+}
+
+// outboxBatchSize bounds how many rows OutboxDispatcher publishes per poll,
+// so one slow RabbitMQ publish can't hold up the dispatcher loop for an
+// unbounded backlog.
+const outboxBatchSize = 50
+
+// outboxMaxBackoff caps how far NextAttemptAt gets pushed out after
+// repeated publish failures.
+const outboxMaxBackoff = 5 * time.Minute
+
+// OutboxDispatcher is the background half of the transactional outbox: it
+// polls for rows OutboxWriter wrote that haven't been published yet,
+// publishes each to RabbitMQ with its stable message_id as Event.ID (so a
+// redelivery after a crash between publish and marking-sent is recognized
+// as a duplicate by messaging.Dedup), and marks them sent. A publish
+// failure retries with exponential backoff rather than dropping the row.
+type OutboxDispatcher struct {
+	db       *gorm.DB
+	rabbitmq *RabbitMQ
+	logger   *logrus.Logger
+}
+
+func NewOutboxDispatcher(db *gorm.DB, rabbitmq *RabbitMQ, logger *logrus.Logger) *OutboxDispatcher {
+	return &OutboxDispatcher{db: db, rabbitmq: rabbitmq, logger: logger}
+}
+
+// Run polls for unpublished rows once every pollInterval until ctx is
+// canceled.
+func (d *OutboxDispatcher) Run(ctx context.Context, pollInterval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				d.dispatchOnce()
+			}
+		}
+	}()
+}
+
+func (d *OutboxDispatcher) dispatchOnce() {
+	var rows []models.OutboxEvent
+	err := d.db.Where("published_at IS NULL AND next_attempt_at <= ?", time.Now()).
+		Order("created_at ASC").
+		Limit(outboxBatchSize).
+		Find(&rows).Error
+	if err != nil {
+		d.logger.WithError(err).Error("outbox: failed to load pending rows")
+		return
+	}
+
+	for _, row := range rows {
+		d.publish(row)
+	}
+}
+
+func (d *OutboxDispatcher) publish(row models.OutboxEvent) {
+	event := Event{
+		ID:        row.MessageID.String(),
+		Type:      row.Type,
+		BoardID:   row.BoardID.String(),
+		UserID:    row.UserID.String(),
+		Data:      map[string]interface{}(row.Data),
+		Timestamp: row.CreatedAt,
+	}
+
+	if err := d.rabbitmq.PublishEvent(event); err != nil {
+		attempts := row.Attempts + 1
+		updates := map[string]interface{}{
+			"attempts":        attempts,
+			"last_error":      err.Error(),
+			"next_attempt_at": time.Now().Add(outboxBackoff(attempts)),
+		}
+		if updateErr := d.db.Model(&models.OutboxEvent{}).Where("message_id = ?", row.MessageID).Updates(updates).Error; updateErr != nil {
+			d.logger.WithError(updateErr).WithField("message_id", row.MessageID).Error("outbox: failed to record publish failure")
+		}
+		d.logger.WithError(err).WithField("message_id", row.MessageID).Warn("outbox: publish failed, will retry")
+		return
+	}
+
+	now := time.Now()
+	if err := d.db.Model(&models.OutboxEvent{}).Where("message_id = ?", row.MessageID).Update("published_at", now).Error; err != nil {
+		d.logger.WithError(err).WithField("message_id", row.MessageID).Error("outbox: failed to mark row published")
+	}
+}
+
+// outboxBackoff doubles the delay each attempt (1s, 2s, 4s, ...), capped at
+// outboxMaxBackoff.
+func outboxBackoff(attempt int) time.Duration {
+	if attempt <= 0 {
+		return time.Second
+	}
+	shift := attempt - 1
+	if shift > 20 {
+		shift = 20
+	}
+	d := time.Second * time.Duration(uint64(1)<<uint(shift))
+	if d > outboxMaxBackoff {
+		return outboxMaxBackoff
+	}
+	return d
+}
+
+// Stats reports how many rows are still unpublished and, if any are, how
+// long the oldest one has been waiting, for the /health/outbox endpoint.
+func (d *OutboxDispatcher) Stats() (pending int64, oldestAge time.Duration, err error) {
+	if err := d.db.Model(&models.OutboxEvent{}).Where("published_at IS NULL").Count(&pending).Error; err != nil {
+		return 0, 0, err
+	}
+	if pending == 0 {
+		return 0, 0, nil
+	}
+
+	var oldest models.OutboxEvent
+	if err := d.db.Where("published_at IS NULL").Order("created_at ASC").First(&oldest).Error; err != nil {
+		return pending, 0, err
+	}
+	return pending, time.Since(oldest.CreatedAt), nil
+}
@@ -1,8 +1,9 @@
 package messaging
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
+	"kanopt/internal/cluster"
 	"kanopt/internal/models"
 	"time"
 
@@ -15,21 +16,148 @@ type EventProcessor struct {
 	db       *gorm.DB
 	rabbitmq *RabbitMQ
 	logger   *logrus.Logger
+	wal      *WAL
+	dedup    *Dedup
+	leader   *cluster.LeaderElector
 }
 
-func NewEventProcessor(db *gorm.DB, rabbitmq *RabbitMQ, logger *logrus.Logger) *EventProcessor {
+// NewEventProcessor wires up an EventProcessor. dedup may be nil, in which
+// case handleEvent processes every delivery as-is and relies solely on
+// RabbitMQ's own redelivery semantics. leader may also be nil, in which
+// case this replica always runs singleton work itself (the pre-cluster,
+// single-instance behavior).
+func NewEventProcessor(db *gorm.DB, rabbitmq *RabbitMQ, logger *logrus.Logger, wal *WAL, dedup *Dedup, leader *cluster.LeaderElector) *EventProcessor {
 	return &EventProcessor{
 		db:       db,
 		rabbitmq: rabbitmq,
 		logger:   logger,
+		wal:      wal,
+		dedup:    dedup,
+		leader:   leader,
 	}
 }
 
-func (ep *EventProcessor) Start() error {
-	return ep.rabbitmq.ConsumeEvents(ep.handleEvent)
+// isLeader reports whether this replica should run singleton work
+// (velocity/cycle-time metrics, bottleneck analysis). With no elector
+// configured, every replica is its own leader.
+func (ep *EventProcessor) isLeader() bool {
+	return ep.leader == nil || ep.leader.IsLeader()
 }
 
-func (ep *EventProcessor) handleEvent(event Event) error {
+// MonitorDLQ registers a callback with rabbitmq that records a
+// "event_pipeline_stalled" RiskPrediction whenever the DLQ's depth
+// crosses threshold, attributed to the board of the event that tipped it
+// over, so a stuck pipeline shows up next to the rest of a board's risk
+// data instead of only in logs.
+func (ep *EventProcessor) MonitorDLQ(threshold int) {
+	ep.rabbitmq.OnDLQThresholdExceeded(threshold, func(depth int, event Event, cause error) {
+		boardID, err := uuid.Parse(event.BoardID)
+		if err != nil {
+			ep.logger.WithError(err).Warn("Cannot attribute stalled event pipeline risk: invalid board ID")
+			return
+		}
+
+		risk := models.RiskPrediction{
+			BoardID:     boardID,
+			Type:        "event_pipeline_stalled",
+			Level:       "high",
+			Score:       1.0,
+			Description: fmt.Sprintf("Event DLQ depth (%d) crossed threshold (%d)", depth, threshold),
+			Data: map[string]interface{}{
+				"dlqDepth":      depth,
+				"threshold":     threshold,
+				"lastEventType": event.Type,
+				"lastError":     cause.Error(),
+			},
+		}
+		if err := ep.db.Create(&risk).Error; err != nil {
+			ep.logger.WithError(err).Error("Failed to record event_pipeline_stalled risk prediction")
+		}
+	})
+}
+
+// ListDLQ, ReplayDLQEvent, and DropDLQEvent are the admin surface for the
+// DLQ: inspecting what landed there, re-running one, or discarding one
+// that's not worth retrying (e.g. caused by bad test data).
+func (ep *EventProcessor) ListDLQ(max int) ([]DLQEntry, error) {
+	return ep.rabbitmq.ListDLQ(max)
+}
+
+func (ep *EventProcessor) ReplayDLQEvent(eventID string) error {
+	return ep.rabbitmq.ReplayDLQ(eventID)
+}
+
+func (ep *EventProcessor) DropDLQEvent(eventID string) error {
+	return ep.rabbitmq.DropDLQ(eventID)
+}
+
+// Start begins consuming events, carrying ctx into every db call the
+// handler chain makes via db.WithContext(ctx). Canceling ctx doesn't stop
+// the underlying RabbitMQ consumer goroutine - that only stops once
+// rabbitmq.Close() tears down the connection - but it does make an
+// in-flight handler's queries fail fast instead of running to completion
+// against a DB that may be shutting down underneath them.
+func (ep *EventProcessor) Start(ctx context.Context) error {
+	return ep.rabbitmq.ConsumeEvents(func(event Event) error {
+		return ep.handleEvent(ctx, event)
+	})
+}
+
+// Reconcile replays every board's WAL at boot and re-applies any event
+// that was appended (and so published) but has no matching models.Event
+// row — the signature of a crash between PublishEvent and handleEvent
+// storing it. It must run before Start begins consuming new events, so
+// reconciliation always sees the full backlog before live traffic starts
+// arriving again.
+func (ep *EventProcessor) Reconcile() error {
+	if ep.wal == nil {
+		return nil
+	}
+
+	for _, boardID := range ep.wal.Boards() {
+		err := ep.wal.ReplayFrom(boardID, 0, func(event Event) error {
+			eventID, err := uuid.Parse(event.ID)
+			if err != nil {
+				return nil
+			}
+
+			var count int64
+			if err := ep.db.Model(&models.Event{}).Where("id = ?", eventID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return nil
+			}
+
+			ep.logger.WithFields(logrus.Fields{
+				"event_id": event.ID,
+				"board_id": boardID,
+				"seq":      event.Seq,
+			}).Warn("Reconciling WAL event that was never fully processed")
+			return ep.handleEvent(context.Background(), event)
+		})
+		if err != nil {
+			return fmt.Errorf("reconciling board %s: %w", boardID, err)
+		}
+	}
+	return nil
+}
+
+func (ep *EventProcessor) handleEvent(ctx context.Context, event Event) error {
+	if ep.dedup != nil {
+		seen, err := ep.dedup.Seen(event.ID)
+		if err != nil {
+			return fmt.Errorf("checking event dedup: %w", err)
+		}
+		if seen {
+			ep.logger.WithFields(logrus.Fields{
+				"event_id":   event.ID,
+				"event_type": event.Type,
+			}).Info("Skipping already-processed event")
+			return nil
+		}
+	}
+
 	ep.logger.WithFields(logrus.Fields{
 		"event_id":   event.ID,
 		"event_type": event.Type,
@@ -37,43 +165,55 @@ func (ep *EventProcessor) handleEvent(event Event) error {
 	}).Info("Processing event")
 
 	// Store event in database
-	if err := ep.storeEvent(event); err != nil {
+	if err := ep.storeEvent(ctx, event); err != nil {
 		return fmt.Errorf("failed to store event: %w", err)
 	}
 
-	// Process different event types
+	if err := ep.dispatchEvent(ctx, event); err != nil {
+		return err
+	}
+
+	if ep.dedup != nil {
+		if err := ep.dedup.MarkProcessed(event.ID); err != nil {
+			return fmt.Errorf("marking event processed: %w", err)
+		}
+	}
+	return nil
+}
+
+func (ep *EventProcessor) dispatchEvent(ctx context.Context, event Event) error {
 	switch event.Type {
 	case "task.created":
-		return ep.handleTaskCreated(event)
+		return ep.handleTaskCreated(ctx, event)
 	case "task.updated":
-		return ep.handleTaskUpdated(event)
+		return ep.handleTaskUpdated(ctx, event)
 	case "task.moved":
-		return ep.handleTaskMoved(event)
+		return ep.handleTaskMoved(ctx, event)
 	case "task.deleted":
-		return ep.handleTaskDeleted(event)
+		return ep.handleTaskDeleted(ctx, event)
 	case "board.created":
-		return ep.handleBoardCreated(event)
+		return ep.handleBoardCreated(ctx, event)
 	case "board.updated":
-		return ep.handleBoardUpdated(event)
+		return ep.handleBoardUpdated(ctx, event)
 	case "column.created":
-		return ep.handleColumnCreated(event)
+		return ep.handleColumnCreated(ctx, event)
 	case "column.updated":
-		return ep.handleColumnUpdated(event)
+		return ep.handleColumnUpdated(ctx, event)
 	default:
 		ep.logger.WithField("event_type", event.Type).Warn("Unknown event type")
 		return nil
 	}
 }
 
-func (ep *EventProcessor) storeEvent(event Event) error {
+func (ep *EventProcessor) storeEvent(ctx context.Context, event Event) error {
 	boardID, err := uuid.Parse(event.BoardID)
 	if err != nil {
-		return fmt.Errorf("invalid board ID: %w", err)
+		return NewPermanentError(fmt.Errorf("invalid board ID: %w", err))
 	}
 
 	userID, err := uuid.Parse(event.UserID)
 	if err != nil {
-		return fmt.Errorf("invalid user ID: %w", err)
+		return NewPermanentError(fmt.Errorf("invalid user ID: %w", err))
 	}
 
 	dbEvent := models.Event{
@@ -84,59 +224,77 @@ func (ep *EventProcessor) storeEvent(event Event) error {
 		Timestamp: event.Timestamp,
 	}
 
-	return ep.db.Create(&dbEvent).Error
+	// Keep the published ID on the row, so Reconcile can tell which WAL
+	// events already have one and an event whose row exists is not stored
+	// a second time.
+	if id, err := uuid.Parse(event.ID); err == nil {
+		dbEvent.ID = id
+		var count int64
+		if err := ep.db.WithContext(ctx).Model(&models.Event{}).Where("id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+	}
+
+	return ep.db.WithContext(ctx).Create(&dbEvent).Error
 }
 
-func (ep *EventProcessor) handleTaskCreated(event Event) error {
+func (ep *EventProcessor) handleTaskCreated(ctx context.Context, event Event) error {
 	// Update velocity metrics
-	return ep.updateVelocityMetrics(event.BoardID)
+	return ep.updateVelocityMetrics(ctx, event.BoardID)
 }
 
-func (ep *EventProcessor) handleTaskUpdated(event Event) error {
+func (ep *EventProcessor) handleTaskUpdated(ctx context.Context, event Event) error {
 	// Check if task was completed
 	if status, ok := event.Data["status"].(string); ok && status == "completed" {
 		// Update completion metrics
-		return ep.updateCompletionMetrics(event.BoardID)
+		return ep.updateCompletionMetrics(ctx, event.BoardID)
 	}
 	return nil
 }
 
-func (ep *EventProcessor) handleTaskMoved(event Event) error {
+func (ep *EventProcessor) handleTaskMoved(ctx context.Context, event Event) error {
 	// Update cycle time metrics
-	if err := ep.updateCycleTimeMetrics(event.BoardID); err != nil {
+	if err := ep.updateCycleTimeMetrics(ctx, event.BoardID); err != nil {
 		return err
 	}
 
 	// Check for bottlenecks
-	return ep.analyzeBottlenecks(event.BoardID)
+	return ep.analyzeBottlenecks(ctx, event.BoardID)
 }
 
-func (ep *EventProcessor) handleTaskDeleted(event Event) error {
+func (ep *EventProcessor) handleTaskDeleted(ctx context.Context, event Event) error {
 	// Update velocity metrics
-	return ep.updateVelocityMetrics(event.BoardID)
+	return ep.updateVelocityMetrics(ctx, event.BoardID)
 }
 
-func (ep *EventProcessor) handleBoardCreated(event Event) error {
+func (ep *EventProcessor) handleBoardCreated(ctx context.Context, event Event) error {
 	// Initialize default columns if not exists
-	return ep.initializeDefaultColumns(event.BoardID)
+	return ep.initializeDefaultColumns(ctx, event.BoardID)
 }
 
-func (ep *EventProcessor) handleBoardUpdated(event Event) error {
+func (ep *EventProcessor) handleBoardUpdated(ctx context.Context, event Event) error {
 	// No specific processing needed for board updates
 	return nil
 }
 
-func (ep *EventProcessor) handleColumnCreated(event Event) error {
+func (ep *EventProcessor) handleColumnCreated(ctx context.Context, event Event) error {
 	// No specific processing needed for column creation
 	return nil
 }
 
-func (ep *EventProcessor) handleColumnUpdated(event Event) error {
+func (ep *EventProcessor) handleColumnUpdated(ctx context.Context, event Event) error {
 	// Check WIP limit violations
-	return ep.checkWIPLimits(event.BoardID)
+	return ep.checkWIPLimits(ctx, event.BoardID)
 }
 
-func (ep *EventProcessor) updateVelocityMetrics(boardIDStr string) error {
+func (ep *EventProcessor) updateVelocityMetrics(ctx context.Context, boardIDStr string) error {
+	if !ep.isLeader() {
+		return nil
+	}
+
 	boardID, err := uuid.Parse(boardIDStr)
 	if err != nil {
 		return err
@@ -145,9 +303,9 @@ func (ep *EventProcessor) updateVelocityMetrics(boardIDStr string) error {
 	// Calculate current week velocity
 	now := time.Now()
 	weekStart := now.AddDate(0, 0, -int(now.Weekday()))
-	
+
 	var completedTasks []models.Task
-	err = ep.db.Where("board_id = ? AND completed_at >= ?", boardID, weekStart).Find(&completedTasks).Error
+	err = ep.db.WithContext(ctx).Where("board_id = ? AND completed_at >= ?", boardID, weekStart).Find(&completedTasks).Error
 	if err != nil {
 		return err
 	}
@@ -172,15 +330,19 @@ func (ep *EventProcessor) updateVelocityMetrics(boardIDStr string) error {
 		Throughput:  len(completedTasks),
 	}
 
-	return ep.db.Save(&velocityMetric).Error
+	return ep.db.WithContext(ctx).Save(&velocityMetric).Error
 }
 
-func (ep *EventProcessor) updateCompletionMetrics(boardIDStr string) error {
+func (ep *EventProcessor) updateCompletionMetrics(ctx context.Context, boardIDStr string) error {
 	// Similar to velocity metrics but focused on completion rates
-	return ep.updateVelocityMetrics(boardIDStr)
+	return ep.updateVelocityMetrics(ctx, boardIDStr)
 }
 
-func (ep *EventProcessor) updateCycleTimeMetrics(boardIDStr string) error {
+func (ep *EventProcessor) updateCycleTimeMetrics(ctx context.Context, boardIDStr string) error {
+	if !ep.isLeader() {
+		return nil
+	}
+
 	boardID, err := uuid.Parse(boardIDStr)
 	if err != nil {
 		return err
@@ -188,7 +350,7 @@ func (ep *EventProcessor) updateCycleTimeMetrics(boardIDStr string) error {
 
 	// Calculate average cycle time for completed tasks
 	var tasks []models.Task
-	err = ep.db.Where("board_id = ? AND completed_at IS NOT NULL", boardID).Find(&tasks).Error
+	err = ep.db.WithContext(ctx).Where("board_id = ? AND completed_at IS NOT NULL", boardID).Find(&tasks).Error
 	if err != nil {
 		return err
 	}
@@ -212,7 +374,7 @@ func (ep *EventProcessor) updateCycleTimeMetrics(boardIDStr string) error {
 	_, week := now.ISOWeek()
 
 	var velocityMetric models.VelocityMetric
-	err = ep.db.Where("board_id = ? AND sprint_week = ?", boardID, week).First(&velocityMetric).Error
+	err = ep.db.WithContext(ctx).Where("board_id = ? AND sprint_week = ?", boardID, week).First(&velocityMetric).Error
 	if err != nil {
 		// Create new if not exists
 		velocityMetric = models.VelocityMetric{
@@ -224,10 +386,14 @@ func (ep *EventProcessor) updateCycleTimeMetrics(boardIDStr string) error {
 		velocityMetric.CycleTime = avgCycleTime
 	}
 
-	return ep.db.Save(&velocityMetric).Error
+	return ep.db.WithContext(ctx).Save(&velocityMetric).Error
 }
 
-func (ep *EventProcessor) analyzeBottlenecks(boardIDStr string) error {
+func (ep *EventProcessor) analyzeBottlenecks(ctx context.Context, boardIDStr string) error {
+	if !ep.isLeader() {
+		return nil
+	}
+
 	boardID, err := uuid.Parse(boardIDStr)
 	if err != nil {
 		return err
@@ -235,7 +401,7 @@ func (ep *EventProcessor) analyzeBottlenecks(boardIDStr string) error {
 
 	// Count tasks in each column
 	var columns []models.Column
-	err = ep.db.Where("board_id = ?", boardID).Preload("Tasks").Find(&columns).Error
+	err = ep.db.WithContext(ctx).Where("board_id = ?", boardID).Preload("Tasks").Find(&columns).Error
 	if err != nil {
 		return err
 	}
@@ -258,7 +424,7 @@ func (ep *EventProcessor) analyzeBottlenecks(boardIDStr string) error {
 				},
 			}
 
-			if err := ep.db.Create(&risk).Error; err != nil {
+			if err := ep.db.WithContext(ctx).Create(&risk).Error; err != nil {
 				ep.logger.WithError(err).Error("Failed to create risk prediction")
 			}
 		}
@@ -267,12 +433,12 @@ func (ep *EventProcessor) analyzeBottlenecks(boardIDStr string) error {
 	return nil
 }
 
-func (ep *EventProcessor) checkWIPLimits(boardIDStr string) error {
+func (ep *EventProcessor) checkWIPLimits(ctx context.Context, boardIDStr string) error {
 	// Similar to bottleneck analysis
-	return ep.analyzeBottlenecks(boardIDStr)
+	return ep.analyzeBottlenecks(ctx, boardIDStr)
 }
 
-func (ep *EventProcessor) initializeDefaultColumns(boardIDStr string) error {
+func (ep *EventProcessor) initializeDefaultColumns(ctx context.Context, boardIDStr string) error {
 	boardID, err := uuid.Parse(boardIDStr)
 	if err != nil {
 		return err
@@ -280,7 +446,7 @@ func (ep *EventProcessor) initializeDefaultColumns(boardIDStr string) error {
 
 	// Check if columns already exist
 	var count int64
-	ep.db.Model(&models.Column{}).Where("board_id = ?", boardID).Count(&count)
+	ep.db.WithContext(ctx).Model(&models.Column{}).Where("board_id = ?", boardID).Count(&count)
 	if count > 0 {
 		return nil // Columns already exist
 	}
@@ -294,5 +460,5 @@ func (ep *EventProcessor) initializeDefaultColumns(boardIDStr string) error {
 		{BoardID: boardID, Name: "Done", Position: 4, WIPLimit: 0},
 	}
 
-	return ep.db.Create(&defaultColumns).Error
+	return ep.db.WithContext(ctx).Create(&defaultColumns).Error
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/rabbitmq/amqp091-go"
 	"github.com/sirupsen/logrus"
 )
@@ -13,6 +14,18 @@ type RabbitMQ struct {
 	conn    *amqp091.Connection
 	channel *amqp091.Channel
 	logger  *logrus.Logger
+	wal     *WAL
+
+	dlqThreshold int
+	onDLQStalled func(depth int, event Event, cause error)
+
+	// codec controls what PublishEvent writes; ConsumeEvents/ConsumeFanout
+	// always accept any codec in codecsByContentType so a rolling upgrade
+	// that changes this can proceed one replica at a time.
+	codec Codec
+	// compressionThreshold is the body size (in bytes, post-codec) at or
+	// above which a publish is gzipped. 0 disables compression.
+	compressionThreshold int
 }
 
 type Event struct {
@@ -22,15 +35,57 @@ type Event struct {
 	UserID    string                 `json:"userId"`
 	Data      map[string]interface{} `json:"data"`
 	Timestamp time.Time              `json:"timestamp"`
+	// Seq is the event's position in its board's WAL, assigned by
+	// PublishEvent when wal is non-nil. Zero means the event was never
+	// appended to a WAL (e.g. WAL disabled), so callers that rely on it
+	// for replay should treat 0 as "no sequence assigned".
+	Seq int64 `json:"seq,omitempty"`
 }
 
 const (
-	EventExchange    = "kanopt.events"
-	EventQueue       = "kanopt.events.queue"
-	EventRoutingKey  = "kanopt.event"
+	EventExchange   = "kanopt.events"
+	EventQueue      = "kanopt.events.queue"
+	EventRoutingKey = "kanopt.event"
+
+	RetryExchange = "kanopt.events.retry"
+	DLQExchange   = "kanopt.events.dlq"
+	DLQQueue      = "kanopt.events.dlq.queue"
+	DLQRoutingKey = "kanopt.event.dlq"
+
+	// retryAttemptHeader tracks how many times an event has already been
+	// retried. It's set on every message published to a retry tier queue
+	// and, since RabbitMQ preserves headers across a dead-letter hop,
+	// survives the TTL expiry that sends the message back to EventQueue.
+	retryAttemptHeader = "x-retry-attempt"
+	dlqReasonHeader    = "x-dlq-reason"
+	dlqFailedAtHeader  = "x-dlq-failed-at"
 )
 
-func NewRabbitMQ(url string, logger *logrus.Logger) (*RabbitMQ, error) {
+// retryTiers are the per-attempt TTL queues a failed event cycles through
+// before landing in the DLQ: each is a queue with no consumer of its own,
+// just a message TTL and a dead-letter-exchange pointing back at
+// EventExchange, so expiry is what redelivers the event for another try.
+var retryTiers = []struct {
+	name string
+	ttl  time.Duration
+}{
+	{"1s", time.Second},
+	{"5s", 5 * time.Second},
+	{"30s", 30 * time.Second},
+	{"5m", 5 * time.Minute},
+}
+
+// maxRetryAttempts is how many times ConsumeEvents retries a transient
+// handler error (via the tiers above) before giving up and routing the
+// event to the DLQ.
+func maxRetryAttempts() int {
+	return len(retryTiers)
+}
+
+// NewRabbitMQ connects and declares the event exchange/queue. wal may be
+// nil, in which case PublishEvent publishes without assigning a sequence
+// number (see Event.Seq).
+func NewRabbitMQ(url string, logger *logrus.Logger, wal *WAL) (*RabbitMQ, error) {
 	conn, err := amqp091.Dial(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
@@ -46,6 +101,8 @@ func NewRabbitMQ(url string, logger *logrus.Logger) (*RabbitMQ, error) {
 		conn:    conn,
 		channel: channel,
 		logger:  logger,
+		wal:     wal,
+		codec:   JSONCodec{},
 	}
 
 	// Setup exchanges and queues
@@ -97,13 +154,128 @@ func (rmq *RabbitMQ) setup() error {
 		return fmt.Errorf("failed to bind queue: %w", err)
 	}
 
+	if err := rmq.setupRetryAndDLQ(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// setupRetryAndDLQ declares the retry exchange and its per-tier TTL
+// queues, plus the DLQ exchange and queue. Each retry tier queue has no
+// consumer; x-message-ttl plus x-dead-letter-exchange/-routing-key is
+// what moves an expired message back onto EventQueue for another attempt.
+func (rmq *RabbitMQ) setupRetryAndDLQ() error {
+	if err := rmq.channel.ExchangeDeclare(RetryExchange, "direct", true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare retry exchange: %w", err)
+	}
+
+	for _, tier := range retryTiers {
+		queueName := fmt.Sprintf("%s.%s", RetryExchange, tier.name)
+		routingKey := fmt.Sprintf("%s.%s", EventRoutingKey+".retry", tier.name)
+
+		_, err := rmq.channel.QueueDeclare(queueName, true, false, false, false, amqp091.Table{
+			"x-message-ttl":             int64(tier.ttl / time.Millisecond),
+			"x-dead-letter-exchange":    EventExchange,
+			"x-dead-letter-routing-key": EventRoutingKey,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to declare retry queue %s: %w", queueName, err)
+		}
+
+		if err := rmq.channel.QueueBind(queueName, routingKey, RetryExchange, false, nil); err != nil {
+			return fmt.Errorf("failed to bind retry queue %s: %w", queueName, err)
+		}
+	}
+
+	if err := rmq.channel.ExchangeDeclare(DLQExchange, "direct", true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare DLQ exchange: %w", err)
+	}
+
+	if _, err := rmq.channel.QueueDeclare(DLQQueue, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare DLQ queue: %w", err)
+	}
+
+	if err := rmq.channel.QueueBind(DLQQueue, DLQRoutingKey, DLQExchange, false, nil); err != nil {
+		return fmt.Errorf("failed to bind DLQ queue: %w", err)
+	}
+
 	return nil
 }
 
+// SetCodec changes the wire format PublishEvent/PublishEventOnce use.
+// Defaults to JSONCodec. Safe to change at runtime for a staged rollout:
+// consumers accept either format regardless of what's currently configured.
+func (rmq *RabbitMQ) SetCodec(codec Codec) {
+	rmq.codec = codec
+}
+
+// SetCompressionThreshold gzips publishes whose encoded body is at least
+// thresholdBytes, tagging them with a Content-Encoding header so
+// ConsumeEvents/ConsumeFanout/ListDLQ transparently decompress them. Pass 0
+// (the default) to disable compression.
+func (rmq *RabbitMQ) SetCompressionThreshold(thresholdBytes int) {
+	rmq.compressionThreshold = thresholdBytes
+}
+
+// encodeEvent marshals event with the configured codec and, if the result
+// is at least compressionThreshold bytes, gzips it. It returns the body to
+// publish plus the ContentType/ContentEncoding headers describing it.
+func (rmq *RabbitMQ) encodeEvent(event Event) (body []byte, contentType, contentEncoding string, err error) {
+	body, err = rmq.codec.Marshal(event)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	body, contentEncoding, err = compressIfLarge(body, rmq.compressionThreshold)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	return body, rmq.codec.ContentType(), contentEncoding, nil
+}
+
+// decodeEvent reverses encodeEvent: it decompresses d.Body per its
+// Content-Encoding header (if any), then unmarshals with the codec matching
+// d.ContentType, falling back to JSON for deliveries published before this
+// package understood content negotiation.
+func decodeEvent(d amqp091.Delivery) (Event, error) {
+	var event Event
+
+	body, err := decompress(d.Body, d.ContentEncoding)
+	if err != nil {
+		return event, err
+	}
+
+	if err := codecForContentType(d.ContentType).Unmarshal(body, &event); err != nil {
+		return event, fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+	return event, nil
+}
+
+// retryRoutingKey returns the routing key for the queue a message at
+// attempt should be published to next.
+func retryRoutingKey(attempt int) string {
+	tier := retryTiers[attempt-1]
+	return fmt.Sprintf("%s.%s", EventRoutingKey+".retry", tier.name)
+}
+
+// PublishEvent appends event to its board's WAL (assigning Event.Seq in
+// the process) before publishing to RabbitMQ, so the sequence a consumer
+// can later replay from is durable even if the broker never delivers this
+// particular message.
 func (rmq *RabbitMQ) PublishEvent(event Event) error {
-	body, err := json.Marshal(event)
+	if rmq.wal != nil {
+		seq, err := rmq.wal.Append(event)
+		if err != nil {
+			return fmt.Errorf("failed to append event to WAL: %w", err)
+		}
+		event.Seq = seq
+	}
+
+	body, contentType, contentEncoding, err := rmq.encodeEvent(event)
 	if err != nil {
-		return fmt.Errorf("failed to marshal event: %w", err)
+		return err
 	}
 
 	err = rmq.channel.Publish(
@@ -112,10 +284,11 @@ func (rmq *RabbitMQ) PublishEvent(event Event) error {
 		false,           // mandatory
 		false,           // immediate
 		amqp091.Publishing{
-			ContentType:  "application/json",
-			DeliveryMode: amqp091.Persistent,
-			Timestamp:    time.Now(),
-			Body:         body,
+			ContentType:     contentType,
+			ContentEncoding: contentEncoding,
+			DeliveryMode:    amqp091.Persistent,
+			Timestamp:       time.Now(),
+			Body:            body,
 		},
 	)
 	if err != nil {
@@ -132,6 +305,30 @@ func (rmq *RabbitMQ) PublishEvent(event Event) error {
 	return nil
 }
 
+// PublishEventOnce publishes event like PublishEvent, except that when the
+// caller hasn't supplied an ID it stamps one deterministically from the
+// type, board, user, and payload instead of leaving it blank. That makes a retry
+// of the exact same logical publish (e.g. an at-least-once background job
+// re-running after a crash) produce the same Event.ID every time, so
+// messaging.Dedup on the consumer side recognizes it as a duplicate rather
+// than double-applying it.
+func (rmq *RabbitMQ) PublishEventOnce(event Event) error {
+	if event.ID == "" {
+		event.ID = deterministicEventID(event)
+	}
+	return rmq.PublishEvent(event)
+}
+
+// deterministicEventID hashes the parts of event that identify it
+// logically (Type, BoardID, UserID, and Data; Timestamp is left out, and
+// so is Seq, which is assigned after the fact by PublishEvent) into a UUID, so the same logical event always maps to the
+// same ID regardless of how many times it's published.
+func deterministicEventID(event Event) string {
+	data, _ := json.Marshal(event.Data)
+	payload := fmt.Sprintf("%s|%s|%s|%s", event.Type, event.BoardID, event.UserID, data)
+	return uuid.NewSHA1(uuid.Nil, []byte(payload)).String()
+}
+
 func (rmq *RabbitMQ) ConsumeEvents(handler func(Event) error) error {
 	msgs, err := rmq.channel.Consume(
 		EventQueue, // queue
@@ -148,27 +345,52 @@ func (rmq *RabbitMQ) ConsumeEvents(handler func(Event) error) error {
 
 	go func() {
 		for d := range msgs {
-			var event Event
-			if err := json.Unmarshal(d.Body, &event); err != nil {
+			event, err := decodeEvent(d)
+			if err != nil {
 				rmq.logger.WithError(err).Error("Failed to unmarshal event")
 				d.Nack(false, false)
 				continue
 			}
 
-			if err := handler(event); err != nil {
-				rmq.logger.WithError(err).WithFields(logrus.Fields{
+			handlerErr := handler(event)
+			if handlerErr == nil {
+				d.Ack(false)
+				rmq.logger.WithFields(logrus.Fields{
 					"event_id":   event.ID,
 					"event_type": event.Type,
-				}).Error("Failed to handle event")
-				d.Nack(false, true)
+				}).Debug("Event processed")
 				continue
 			}
 
+			fields := logrus.Fields{"event_id": event.ID, "event_type": event.Type}
+
+			attempt := attemptFromHeaders(d.Headers)
+			permanent := IsPermanent(handlerErr)
+
+			if !permanent && attempt < maxRetryAttempts() {
+				nextAttempt := attempt + 1
+				if err := rmq.publishToRetry(event, nextAttempt); err != nil {
+					rmq.logger.WithError(err).WithFields(fields).Error("Failed to publish to retry queue; falling back to native requeue")
+					d.Nack(false, true)
+					continue
+				}
+				rmq.logger.WithError(handlerErr).WithFields(fields).WithField("retry_attempt", nextAttempt).Warn("Event handling failed; scheduled for retry")
+				d.Ack(false)
+				continue
+			}
+
+			if err := rmq.publishToDLQ(event, attempt, handlerErr); err != nil {
+				rmq.logger.WithError(err).WithFields(fields).Error("Failed to publish to DLQ; falling back to native requeue")
+				d.Nack(false, true)
+				continue
+			}
+			rmq.logger.WithError(handlerErr).WithFields(fields).WithFields(logrus.Fields{
+				"permanent": permanent,
+				"attempts":  attempt,
+			}).Error("Event handling failed permanently; routed to DLQ")
 			d.Ack(false)
-			rmq.logger.WithFields(logrus.Fields{
-				"event_id":   event.ID,
-				"event_type": event.Type,
-			}).Debug("Event processed")
+
+			rmq.checkDLQThreshold(event, handlerErr)
 		}
 	}()
 
@@ -176,6 +398,309 @@ func (rmq *RabbitMQ) ConsumeEvents(handler func(Event) error) error {
 	return nil
 }
 
+// attemptFromHeaders reads retryAttemptHeader off a delivery, treating a
+// missing or unrecognized value as attempt 0 (never retried).
+func attemptFromHeaders(headers amqp091.Table) int {
+	switch v := headers[retryAttemptHeader].(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	default:
+		return 0
+	}
+}
+
+// publishToRetry publishes event to the TTL queue for attempt, stamping
+// retryAttemptHeader so the next failure (or the DLQ, if attempts run out)
+// knows how many tries it's had.
+func (rmq *RabbitMQ) publishToRetry(event Event, attempt int) error {
+	body, contentType, contentEncoding, err := rmq.encodeEvent(event)
+	if err != nil {
+		return err
+	}
+
+	return rmq.channel.Publish(RetryExchange, retryRoutingKey(attempt), false, false, amqp091.Publishing{
+		ContentType:     contentType,
+		ContentEncoding: contentEncoding,
+		DeliveryMode:    amqp091.Persistent,
+		Timestamp:       time.Now(),
+		Headers:         amqp091.Table{retryAttemptHeader: int32(attempt)},
+		Body:            body,
+	})
+}
+
+// publishToDLQ publishes event to the DLQ, recording how many attempts it
+// had and why the last one failed so the admin API and any human looking
+// at the queue can tell what went wrong.
+func (rmq *RabbitMQ) publishToDLQ(event Event, attempt int, cause error) error {
+	body, contentType, contentEncoding, err := rmq.encodeEvent(event)
+	if err != nil {
+		return err
+	}
+
+	return rmq.channel.Publish(DLQExchange, DLQRoutingKey, false, false, amqp091.Publishing{
+		ContentType:     contentType,
+		ContentEncoding: contentEncoding,
+		DeliveryMode:    amqp091.Persistent,
+		Timestamp:       time.Now(),
+		Headers: amqp091.Table{
+			retryAttemptHeader: int32(attempt),
+			dlqReasonHeader:    cause.Error(),
+			dlqFailedAtHeader:  time.Now().Format(time.RFC3339),
+		},
+		Body: body,
+	})
+}
+
+// OnDLQThresholdExceeded registers fn to run whenever a DLQ publish leaves
+// the queue's depth at or above threshold. Pass threshold <= 0 to disable.
+func (rmq *RabbitMQ) OnDLQThresholdExceeded(threshold int, fn func(depth int, event Event, cause error)) {
+	rmq.dlqThreshold = threshold
+	rmq.onDLQStalled = fn
+}
+
+// checkDLQThreshold inspects the DLQ's depth after event was routed to it
+// and, if a threshold is configured and crossed, invokes the registered
+// callback so the caller can surface the stall (e.g. as a RiskPrediction).
+func (rmq *RabbitMQ) checkDLQThreshold(event Event, cause error) {
+	if rmq.onDLQStalled == nil || rmq.dlqThreshold <= 0 {
+		return
+	}
+	depth, err := rmq.DLQDepth()
+	if err != nil {
+		rmq.logger.WithError(err).Warn("Failed to inspect DLQ depth")
+		return
+	}
+	if depth >= rmq.dlqThreshold {
+		rmq.onDLQStalled(depth, event, cause)
+	}
+}
+
+// DLQDepth returns the number of messages currently sitting in the DLQ.
+func (rmq *RabbitMQ) DLQDepth() (int, error) {
+	q, err := rmq.channel.QueueInspect(DLQQueue)
+	if err != nil {
+		return 0, fmt.Errorf("failed to inspect DLQ: %w", err)
+	}
+	return q.Messages, nil
+}
+
+// DLQEntry is one message sitting in the DLQ, as returned by ListDLQ.
+type DLQEntry struct {
+	Event    Event     `json:"event"`
+	Attempts int       `json:"attempts"`
+	Reason   string    `json:"reason"`
+	FailedAt time.Time `json:"failedAt"`
+}
+
+// ListDLQ returns up to max messages currently in the DLQ, without
+// removing them: each message is popped and immediately republished so
+// the queue's contents are left unchanged. It's meant for admin
+// inspection, not high-frequency polling.
+func (rmq *RabbitMQ) ListDLQ(max int) ([]DLQEntry, error) {
+	// Republished messages go to the tail, so reading past the current
+	// depth would list the same messages again.
+	depth, err := rmq.DLQDepth()
+	if err != nil {
+		return nil, err
+	}
+	if max > depth {
+		max = depth
+	}
+
+	var entries []DLQEntry
+	for i := 0; i < max; i++ {
+		d, ok, err := rmq.channel.Get(DLQQueue, false)
+		if err != nil {
+			return entries, fmt.Errorf("failed to read DLQ: %w", err)
+		}
+		if !ok {
+			break
+		}
+
+		entry, parseErr := parseDLQDelivery(d)
+		if parseErr != nil {
+			rmq.logger.WithError(parseErr).Warn("Dropping unparseable DLQ message")
+			d.Ack(false)
+			continue
+		}
+		entries = append(entries, entry)
+
+		if err := rmq.republishDLQ(d); err != nil {
+			d.Ack(false)
+			return entries, fmt.Errorf("failed to restore DLQ message after listing: %w", err)
+		}
+		d.Ack(false)
+	}
+	return entries, nil
+}
+
+// ReplayDLQ removes the DLQ message for eventID and republishes it onto
+// the main event exchange for reprocessing. Every other message the scan
+// passes over along the way is left in the DLQ untouched.
+func (rmq *RabbitMQ) ReplayDLQ(eventID string) error {
+	return rmq.drainDLQUntil(eventID, func(event Event) error {
+		body, contentType, contentEncoding, err := rmq.encodeEvent(event)
+		if err != nil {
+			return err
+		}
+		return rmq.channel.Publish(EventExchange, EventRoutingKey, false, false, amqp091.Publishing{
+			ContentType:     contentType,
+			ContentEncoding: contentEncoding,
+			DeliveryMode:    amqp091.Persistent,
+			Timestamp:       time.Now(),
+			Body:            body,
+		})
+	})
+}
+
+// DropDLQ permanently removes the DLQ message for eventID.
+func (rmq *RabbitMQ) DropDLQ(eventID string) error {
+	return rmq.drainDLQUntil(eventID, func(Event) error { return nil })
+}
+
+// drainDLQUntil scans the DLQ for the message whose Event.ID is eventID.
+// Every other message it passes over is republished back onto the DLQ
+// unchanged; the matching message is removed and passed to onMatch, whose
+// return value decides whether it's gone for good (nil) or restored (any
+// other error, e.g. a replay publish failing).
+func (rmq *RabbitMQ) drainDLQUntil(eventID string, onMatch func(Event) error) error {
+	depth, err := rmq.DLQDepth()
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < depth; i++ {
+		d, ok, err := rmq.channel.Get(DLQQueue, false)
+		if err != nil {
+			return fmt.Errorf("failed to read DLQ: %w", err)
+		}
+		if !ok {
+			break
+		}
+
+		event, err := decodeEvent(d)
+		if err != nil {
+			rmq.logger.WithError(err).Warn("Dropping unparseable DLQ message")
+			d.Ack(false)
+			continue
+		}
+
+		if event.ID != eventID {
+			if err := rmq.republishDLQ(d); err != nil {
+				d.Ack(false)
+				return fmt.Errorf("failed to restore DLQ message: %w", err)
+			}
+			d.Ack(false)
+			continue
+		}
+
+		if matchErr := onMatch(event); matchErr != nil {
+			if err := rmq.republishDLQ(d); err != nil {
+				rmq.logger.WithError(err).Error("Failed to restore DLQ message after failed replay")
+			}
+			d.Ack(false)
+			return matchErr
+		}
+		d.Ack(false)
+		return nil
+	}
+
+	return fmt.Errorf("no DLQ message found with event id %s", eventID)
+}
+
+// republishDLQ puts d back on the DLQ with the same body, headers,
+// ContentType and ContentEncoding it arrived with, so decodeEvent can still
+// read it afterwards.
+func (rmq *RabbitMQ) republishDLQ(d amqp091.Delivery) error {
+	return rmq.channel.Publish(DLQExchange, DLQRoutingKey, false, false, amqp091.Publishing{
+		ContentType:     d.ContentType,
+		ContentEncoding: d.ContentEncoding,
+		DeliveryMode:    amqp091.Persistent,
+		Timestamp:       time.Now(),
+		Headers:         d.Headers,
+		Body:            d.Body,
+	})
+}
+
+func parseDLQDelivery(d amqp091.Delivery) (DLQEntry, error) {
+	event, err := decodeEvent(d)
+	if err != nil {
+		return DLQEntry{}, err
+	}
+
+	entry := DLQEntry{Event: event, Attempts: attemptFromHeaders(d.Headers)}
+	if reason, ok := d.Headers[dlqReasonHeader].(string); ok {
+		entry.Reason = reason
+	}
+	if failedAt, ok := d.Headers[dlqFailedAtHeader].(string); ok {
+		if t, err := time.Parse(time.RFC3339, failedAt); err == nil {
+			entry.FailedAt = t
+		}
+	}
+	return entry, nil
+}
+
+// ConsumeFanout registers a new exclusive, auto-deleted queue bound to the
+// event exchange and invokes handler for every event published to it. Unlike
+// ConsumeEvents (which uses the shared durable EventQueue and competes with
+// the event processor for messages), each caller gets its own queue and
+// therefore its own copy of every event — this is what lets, e.g., several
+// SSE broadcasters each see the full event stream.
+func (rmq *RabbitMQ) ConsumeFanout(handler func(Event)) error {
+	queue, err := rmq.channel.QueueDeclare(
+		"",    // name: let the server generate one
+		false, // durable
+		true,  // delete when unused
+		true,  // exclusive
+		false, // no-wait
+		nil,   // arguments
+	)
+	if err != nil {
+		return fmt.Errorf("failed to declare fanout queue: %w", err)
+	}
+
+	err = rmq.channel.QueueBind(
+		queue.Name,      // queue name
+		EventRoutingKey, // routing key
+		EventExchange,   // exchange
+		false,
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to bind fanout queue: %w", err)
+	}
+
+	msgs, err := rmq.channel.Consume(
+		queue.Name, // queue
+		"",         // consumer
+		true,       // auto-ack: best-effort, replay comes from the DB instead
+		true,       // exclusive
+		false,      // no-local
+		false,      // no-wait
+		nil,        // args
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register fanout consumer: %w", err)
+	}
+
+	go func() {
+		for d := range msgs {
+			event, err := decodeEvent(d)
+			if err != nil {
+				rmq.logger.WithError(err).Error("Failed to unmarshal fanout event")
+				continue
+			}
+			handler(event)
+		}
+	}()
+
+	return nil
+}
+
 func (rmq *RabbitMQ) Close() error {
 	if rmq.channel != nil {
 		rmq.channel.Close()
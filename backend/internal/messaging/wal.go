@@ -0,0 +1,344 @@
+package messaging
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WAL is a durable, per-board append-only log of every Event published
+// through it. It gives each event a sequence number that's monotonically
+// increasing within its board, so a consumer that fell behind or a
+// process that crashed mid-flight can replay exactly what it missed
+// instead of relying on RabbitMQ redelivery alone.
+//
+// It sits between RabbitMQ.PublishEvent and EventProcessor.handleEvent:
+// PublishEvent appends to the WAL first (stamping Event.Seq in the
+// process) and only then publishes, and EventProcessor.Reconcile replays
+// it at boot to re-apply anything that was appended but never finished
+// processing before the crash.
+//
+// Each board gets its own segment file under dir named <boardID>.wal,
+// holding back-to-back length-prefixed JSON records. That's the same
+// idea as tidwall/wal or an LSM WAL, just without the multi-segment
+// rotation scheme — one growing file per board plus periodic compaction
+// is enough for this volume of events.
+type WAL struct {
+	dir      string
+	maxAge   time.Duration
+	maxBytes int64
+	logger   *logrus.Logger
+
+	mu      sync.Mutex
+	lastSeq map[string]int64
+	files   map[string]*os.File
+}
+
+// walRecord is one length-prefixed frame in a board's segment file.
+type walRecord struct {
+	Seq   int64 `json:"seq"`
+	Event Event `json:"event"`
+}
+
+// NewWAL opens (creating if necessary) the segment directory dir and
+// recovers each board's last sequence number by scanning its existing
+// segment file. maxAge and maxBytes bound how much of each board's log
+// Append retains; either can be zero to disable that bound.
+func NewWAL(dir string, maxAge time.Duration, maxBytes int64, logger *logrus.Logger) (*WAL, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("wal: creating dir: %w", err)
+	}
+
+	w := &WAL{
+		dir:      dir,
+		maxAge:   maxAge,
+		maxBytes: maxBytes,
+		logger:   logger,
+		lastSeq:  make(map[string]int64),
+		files:    make(map[string]*os.File),
+	}
+	if err := w.recoverSeqs(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *WAL) recoverSeqs() error {
+	matches, err := filepath.Glob(filepath.Join(w.dir, "*.wal"))
+	if err != nil {
+		return fmt.Errorf("wal: listing segments: %w", err)
+	}
+
+	for _, path := range matches {
+		boardID := strings.TrimSuffix(filepath.Base(path), ".wal")
+		records, err := readSegment(path)
+		if err != nil {
+			return fmt.Errorf("wal: recovering %s: %w", boardID, err)
+		}
+		if len(records) > 0 {
+			w.lastSeq[boardID] = records[len(records)-1].Seq
+		}
+	}
+	return nil
+}
+
+// Append assigns boardID the next sequence number, stamps it onto a copy
+// of event, and durably appends it to that board's segment file,
+// returning the assigned sequence.
+func (w *WAL) Append(event Event) (int64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	boardID := event.BoardID
+	seq := w.lastSeq[boardID] + 1
+	event.Seq = seq
+
+	f, err := w.fileFor(boardID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := writeRecord(f, walRecord{Seq: seq, Event: event}); err != nil {
+		return 0, fmt.Errorf("wal: appending to %s: %w", boardID, err)
+	}
+
+	w.lastSeq[boardID] = seq
+	w.maybeCompact(boardID, f)
+
+	return seq, nil
+}
+
+// ReplayFrom calls handler, in sequence order, for every event appended
+// for boardID with a sequence strictly greater than sinceSeq. It stops
+// and returns handler's error if handler fails partway through.
+func (w *WAL) ReplayFrom(boardID string, sinceSeq int64, handler func(Event) error) error {
+	records, err := readSegment(w.segmentPath(boardID))
+	if err != nil {
+		return fmt.Errorf("wal: reading %s: %w", boardID, err)
+	}
+
+	for _, rec := range records {
+		if rec.Seq <= sinceSeq {
+			continue
+		}
+		if err := handler(rec.Event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Boards returns the IDs of every board with at least one WAL-tracked
+// event, sorted for deterministic iteration (e.g. at boot reconciliation).
+func (w *WAL) Boards() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	boards := make([]string, 0, len(w.lastSeq))
+	for boardID := range w.lastSeq {
+		boards = append(boards, boardID)
+	}
+	sort.Strings(boards)
+	return boards
+}
+
+// LastSeq returns the highest sequence number appended for boardID, or 0
+// if nothing has been appended yet.
+func (w *WAL) LastSeq(boardID string) int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lastSeq[boardID]
+}
+
+// Close releases every open segment file handle.
+func (w *WAL) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var firstErr error
+	for _, f := range w.files {
+		if err := f.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (w *WAL) segmentPath(boardID string) string {
+	return filepath.Join(w.dir, boardID+".wal")
+}
+
+func (w *WAL) fileFor(boardID string) (*os.File, error) {
+	if f, ok := w.files[boardID]; ok {
+		return f, nil
+	}
+
+	f, err := os.OpenFile(w.segmentPath(boardID), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("wal: opening segment for %s: %w", boardID, err)
+	}
+	w.files[boardID] = f
+	return f, nil
+}
+
+// maybeCompact drops records older than maxAge and, if the segment still
+// exceeds maxBytes, trims the oldest remaining records until it doesn't.
+// Compaction never removes the newest record, so a board always keeps at
+// least its latest event regardless of age or size bounds. Called with
+// w.mu already held.
+func (w *WAL) maybeCompact(boardID string, f *os.File) {
+	if w.maxAge <= 0 && w.maxBytes <= 0 {
+		return
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return
+	}
+	sizeExceeded := w.maxBytes > 0 && info.Size() > w.maxBytes
+	if !sizeExceeded && w.maxAge <= 0 {
+		return
+	}
+
+	records, err := readSegment(w.segmentPath(boardID))
+	if err != nil || len(records) == 0 {
+		return
+	}
+
+	kept := records
+	if w.maxAge > 0 {
+		cutoff := time.Now().Add(-w.maxAge)
+		trimmed := kept[:0:0]
+		for _, rec := range kept {
+			if rec.Event.Timestamp.After(cutoff) {
+				trimmed = append(trimmed, rec)
+			}
+		}
+		if len(trimmed) == 0 {
+			trimmed = kept[len(kept)-1:]
+		}
+		kept = trimmed
+	}
+
+	if w.maxBytes > 0 {
+		for len(kept) > 1 && approxSize(kept) > w.maxBytes {
+			kept = kept[1:]
+		}
+	}
+
+	if len(kept) == len(records) {
+		return
+	}
+
+	if err := w.rewriteSegment(boardID, kept); err != nil {
+		w.logger.WithError(err).WithField("board_id", boardID).Warn("WAL: compaction failed")
+	}
+}
+
+func approxSize(records []walRecord) int64 {
+	var total int64
+	for _, rec := range records {
+		body, err := json.Marshal(rec)
+		if err != nil {
+			continue
+		}
+		total += int64(len(body)) + 4
+	}
+	return total
+}
+
+func (w *WAL) rewriteSegment(boardID string, records []walRecord) error {
+	if f, ok := w.files[boardID]; ok {
+		f.Close()
+		delete(w.files, boardID)
+	}
+
+	tmpPath := w.segmentPath(boardID) + ".compact"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	for _, rec := range records {
+		if err := writeRecord(tmp, rec); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, w.segmentPath(boardID)); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(w.segmentPath(boardID), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	w.files[boardID] = f
+	return nil
+}
+
+func writeRecord(w io.Writer, rec walRecord) error {
+	body, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	var lenBuf [4]byte
+	binary.BigEndian.PutUint32(lenBuf[:], uint32(len(body)))
+	if _, err := w.Write(lenBuf[:]); err != nil {
+		return err
+	}
+	_, err = w.Write(body)
+	return err
+}
+
+// readSegment reads every complete record from a board's segment file. A
+// missing file reads as an empty log. A truncated trailing record (the
+// signature of a crash mid-write) ends the read there rather than
+// failing the whole read, so a partial write never blocks replay of
+// everything before it.
+func readSegment(path string) ([]walRecord, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []walRecord
+	var lenBuf [4]byte
+	for {
+		if _, err := io.ReadFull(f, lenBuf[:]); err != nil {
+			if err == io.EOF {
+				break
+			}
+			break // partial length prefix: truncated by a crash, stop here
+		}
+
+		size := binary.BigEndian.Uint32(lenBuf[:])
+		body := make([]byte, size)
+		if _, err := io.ReadFull(f, body); err != nil {
+			break // partial body: truncated by a crash, stop here
+		}
+
+		var rec walRecord
+		if err := json.Unmarshal(body, &rec); err != nil {
+			break
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
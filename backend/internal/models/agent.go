@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Suggestion struct {
+	ID       uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	BoardID  uuid.UUID `json:"boardId" gorm:"type:uuid;index"`
+	Type     string    `json:"type"`
+	Title    string    `json:"title"`
+	Status   string    `json:"status" gorm:"index"` // pending, approved, rejected
+	Priority int       `json:"priority"`
+	Data     JSONMap   `json:"data" gorm:"type:jsonb"`
+	// Version backs the optimistic-concurrency check ApproveSuggestion/
+	// RejectSuggestion run as a conditional UPDATE (WHERE status='pending'
+	// AND version=?), so two reviewers racing to approve/reject the same
+	// suggestion can't both "win" and create duplicate agent actions.
+	Version   int       `json:"version" gorm:"default:1"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type AgentAction struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	BoardID     uuid.UUID `json:"boardId" gorm:"type:uuid;index"`
+	Type        string    `json:"type"`
+	Description string    `json:"description"`
+	Status      string    `json:"status" gorm:"index"` // pending, completed, failed, rolled_back
+	Data        JSONMap   `json:"data" gorm:"type:jsonb"`
+	// CreatedBy is the authenticated reviewer whose approval spawned this
+	// action (set by ApproveSuggestion), so "who did this" survives
+	// independently of the AuditLog trail.
+	CreatedBy uuid.UUID `json:"createdBy" gorm:"type:uuid;index"`
+	// RollbackData is the inverse-operation snapshot executeAgentAction's
+	// per-type helper recorded when the action completed (previous
+	// AssigneeID/WIPLimit, created subtask IDs) - everything
+	// RollbackAgentAction needs to undo it without re-deriving state from
+	// the current board.
+	RollbackData JSONMap    `json:"rollbackData,omitempty" gorm:"type:jsonb"`
+	ExecutedAt   *time.Time `json:"executedAt"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AgentPolicy is one board's raw policy document, as submitted to
+// /api/agent/policies/:boardId. Document is stored exactly as received
+// (see internal/allocator/policy.Parse for the format it's parsed with)
+// so a board's policy can be round-tripped unchanged through GET/PUT.
+type AgentPolicy struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	BoardID   uuid.UUID `json:"boardId" gorm:"type:uuid;uniqueIndex"`
+	Format    string    `json:"format"` // "yaml" or "json"
+	Document  string    `json:"document" gorm:"type:text"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type VelocityMetric struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	BoardID     uuid.UUID  `json:"boardId" gorm:"type:uuid;index"`
+	SprintID    *uuid.UUID `json:"sprintId" gorm:"type:uuid;index"`
+	SprintWeek  int        `json:"sprintWeek"`
+	Velocity    float64    `json:"velocity"`
+	Completed   int        `json:"completed"`
+	TotalPoints int        `json:"totalPoints"`
+	Throughput  int        `json:"throughput"`
+	CycleTime   float64    `json:"cycleTime"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+type RiskPrediction struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	BoardID     uuid.UUID  `json:"boardId" gorm:"type:uuid;index"`
+	TaskID      *uuid.UUID `json:"taskId" gorm:"type:uuid"`
+	Type        string     `json:"type"`
+	Level       string     `json:"level"`
+	Score       float64    `json:"score"`
+	Description string     `json:"description"`
+	Data        JSONMap    `json:"data" gorm:"type:jsonb"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
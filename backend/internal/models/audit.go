@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditLog records one mutation an operator can later trace end-to-end via
+// its CorrelationID: the same ID is threaded into the handler's
+// messaging.Event.Data, so GET /api/audit can join a suggestion approval to
+// the agent action it created, the RabbitMQ event that carried it, and the
+// WebSocket broadcast downstream of that.
+type AuditLog struct {
+	ID            uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	CorrelationID string    `json:"correlationId" gorm:"index"`
+	BoardID       uuid.UUID `json:"boardId" gorm:"type:uuid;index"`
+	Actor         string    `json:"actor"`
+	Action        string    `json:"action" gorm:"index"`
+	ResourceType  string    `json:"resourceType"`
+	ResourceID    string    `json:"resourceId"`
+	Before        JSONMap   `json:"before,omitempty" gorm:"type:jsonb"`
+	After         JSONMap   `json:"after,omitempty" gorm:"type:jsonb"`
+	Outcome       string    `json:"outcome"` // success, failure
+	CreatedAt     time.Time `json:"createdAt"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Board struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Name        string    `json:"name" binding:"required"`
+	Description string    `json:"description"`
+	CreatedBy   uuid.UUID `json:"createdBy" gorm:"type:uuid"`
+	UpdatedBy   uuid.UUID `json:"updatedBy" gorm:"type:uuid"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+
+	Columns []Column `json:"columns,omitempty" gorm:"foreignKey:BoardID"`
+	Tasks   []Task   `json:"tasks,omitempty" gorm:"foreignKey:BoardID"`
+}
+
+type Column struct {
+	ID       uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	BoardID  uuid.UUID `json:"boardId" gorm:"type:uuid;index"`
+	Name     string    `json:"name"`
+	Position int       `json:"position"`
+	WIPLimit int       `json:"wipLimit"`
+
+	Tasks []Task `json:"tasks,omitempty" gorm:"foreignKey:ColumnID"`
+}
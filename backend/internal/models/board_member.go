@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BoardMember grants a user a role on a board. Its presence is what
+// AuthMiddleware-gated handlers check before allowing a write against a
+// board or one of its tasks; see ensureBoardMember and ensureBoardRole in
+// internal/api/auth.go.
+type BoardMember struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	BoardID   uuid.UUID `json:"boardId" gorm:"type:uuid;index;uniqueIndex:idx_board_members_board_user"`
+	UserID    uuid.UUID `json:"userId" gorm:"type:uuid;index;uniqueIndex:idx_board_members_board_user"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Event struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	BoardID   uuid.UUID `json:"boardId" gorm:"type:uuid;index"`
+	Type      string    `json:"type" gorm:"index"`
+	UserID    uuid.UUID `json:"userId" gorm:"type:uuid"`
+	User      *User     `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Data      JSONMap   `json:"data" gorm:"type:jsonb"`
+	Timestamp time.Time `json:"timestamp" gorm:"index"`
+}
+
+// ArchivedEvent is one Event's payload as it's stored inside an
+// EventArchive row, keeping everything needed to reconstruct the original
+// Event on lookup.
+type ArchivedEvent struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"userId"`
+	Data      JSONMap   `json:"data"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ArchivedEventList stores a []ArchivedEvent as a JSON array column.
+type ArchivedEventList []ArchivedEvent
+
+func (a ArchivedEventList) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+	return json.Marshal(a)
+}
+
+func (a *ArchivedEventList) Scan(value interface{}) error {
+	if value == nil {
+		*a = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		str, ok := value.(string)
+		if !ok {
+			return errors.New("models: ArchivedEventList scan source is not []byte or string")
+		}
+		bytes = []byte(str)
+	}
+
+	if len(bytes) == 0 {
+		*a = nil
+		return nil
+	}
+	return json.Unmarshal(bytes, a)
+}
+
+// ProcessedEvent is the authoritative record that an event has already
+// been run through EventProcessor.handleEvent. It backs messaging.Dedup's
+// "maybe seen" path: the bloom filter rules most redeliveries out in
+// memory, but a positive bloom match still has to be confirmed against
+// this table before a handler is skipped, since bloom filters can false
+// positive but never false negative.
+type ProcessedEvent struct {
+	EventID     uuid.UUID `json:"eventId" gorm:"type:uuid;primaryKey"`
+	ProcessedAt time.Time `json:"processedAt" gorm:"autoCreateTime"`
+}
+
+// EventArchive is a cold-storage rollup of Event rows, one per
+// board+day+type, holding the full payloads as a JSON array plus an
+// aggregate count. This keeps the hot Event table bounded while preserving
+// the full audit trail: CreateEvent still writes every event to Event, and
+// an on-demand archive pass (api.ArchiveBoardEvents) moves anything older than a cutoff
+// into rows like this one, merging into the same board+day+type bucket if
+// it already exists.
+type EventArchive struct {
+	ID        uuid.UUID         `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	BoardID   uuid.UUID         `json:"boardId" gorm:"type:uuid;uniqueIndex:idx_event_archive_bucket"`
+	Day       time.Time         `json:"day" gorm:"type:date;uniqueIndex:idx_event_archive_bucket"`
+	Type      string            `json:"type" gorm:"uniqueIndex:idx_event_archive_bucket"`
+	Count     int               `json:"count"`
+	Payloads  ArchivedEventList `json:"payloads" gorm:"type:jsonb"`
+	CreatedAt time.Time         `json:"createdAt"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Import job statuses.
+const (
+	ImportJobStatusPending   = "pending"
+	ImportJobStatusRunning   = "running"
+	ImportJobStatusCompleted = "completed"
+	ImportJobStatusFailed    = "failed"
+)
+
+// ImportJob tracks a background data import's progress. Cursor holds
+// source-specific resume state (e.g. a Jira search `startAt` offset) so a
+// failed or restarted run can pick up where it left off instead of
+// re-importing everything.
+type ImportJob struct {
+	ID              uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	BoardID         uuid.UUID `json:"boardId" gorm:"type:uuid;index"`
+	Source          string    `json:"source"` // "jira"
+	Status          string    `json:"status" gorm:"index"`
+	Cursor          int       `json:"cursor"`
+	TasksImported   int       `json:"tasksImported"`
+	SprintsImported int       `json:"sprintsImported"`
+	EventsEmitted   int       `json:"eventsEmitted"`
+	Error           string    `json:"error"`
+	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
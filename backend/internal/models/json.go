@@ -0,0 +1,44 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// JSONMap is a map[string]interface{} that reads/writes as JSONB in Postgres.
+type JSONMap map[string]interface{}
+
+func (m JSONMap) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return json.Marshal(m)
+}
+
+func (m *JSONMap) Scan(value interface{}) error {
+	if value == nil {
+		*m = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		str, ok := value.(string)
+		if !ok {
+			return errors.New("models: JSONMap scan source is not []byte or string")
+		}
+		bytes = []byte(str)
+	}
+
+	result := make(JSONMap)
+	if len(bytes) == 0 {
+		*m = result
+		return nil
+	}
+	if err := json.Unmarshal(bytes, &result); err != nil {
+		return err
+	}
+	*m = result
+	return nil
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxEvent is a not-yet-published messaging.Event, written inside the
+// same transaction as the mutation that produced it so the two can never
+// diverge: either both commit, or neither does. messaging.OutboxDispatcher
+// polls rows with PublishedAt nil and NextAttemptAt due, publishes them,
+// and marks them sent; a publish failure bumps Attempts and pushes
+// NextAttemptAt out with backoff instead of losing the event.
+type OutboxEvent struct {
+	MessageID     uuid.UUID  `json:"messageId" gorm:"type:uuid;primaryKey"`
+	BoardID       uuid.UUID  `json:"boardId" gorm:"type:uuid;index"`
+	Type          string     `json:"type"`
+	UserID        uuid.UUID  `json:"userId" gorm:"type:uuid"`
+	Data          JSONMap    `json:"data" gorm:"type:jsonb"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	PublishedAt   *time.Time `json:"publishedAt"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"nextAttemptAt" gorm:"index"`
+	LastError     string     `json:"lastError"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScheduledAction is a recurring agent action template: scheduler.Scheduler
+// parses CronExpr with robfig/cron, and at each due NextRunAt creates a
+// pending AgentAction (Type=ActionType, Data=Data) for BoardID and dispatches
+// it through the same path ExecuteAgentAction uses for a manually-triggered
+// one. Disabling a schedule (Enabled=false) stops new runs without deleting
+// the row, so its NextRunAt/LastRunAt history survives re-enabling it.
+type ScheduledAction struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	BoardID    uuid.UUID  `json:"boardId" gorm:"type:uuid;index"`
+	ActionType string     `json:"actionType"`
+	CronExpr   string     `json:"cronExpr"`
+	Data       JSONMap    `json:"data" gorm:"type:jsonb"`
+	Enabled    bool       `json:"enabled" gorm:"default:true;index"`
+	NextRunAt  *time.Time `json:"nextRunAt"`
+	LastRunAt  *time.Time `json:"lastRunAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sprint statuses.
+const (
+	SprintStatusPlanned   = "planned"
+	SprintStatusActive    = "active"
+	SprintStatusCompleted = "completed"
+)
+
+type Sprint struct {
+	ID                   uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	BoardID              uuid.UUID `json:"boardId" gorm:"type:uuid;index"`
+	Name                 string    `json:"name" binding:"required"`
+	Goal                 string    `json:"goal"`
+	StartAt              time.Time `json:"startAt" binding:"required"`
+	EndAt                time.Time `json:"endAt" binding:"required"`
+	CommittedStoryPoints int       `json:"committedStoryPoints"`
+	Status               string    `json:"status" gorm:"index"`
+	CreatedAt            time.Time `json:"createdAt"`
+	UpdatedAt            time.Time `json:"updatedAt"`
+}
+
+// SprintDayStat is a daily snapshot of a sprint's burndown, recomputed by the
+// sprint worker on a schedule and whenever a task's status or points change.
+type SprintDayStat struct {
+	ID              uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	SprintID        uuid.UUID `json:"sprintId" gorm:"type:uuid;uniqueIndex:idx_sprint_day"`
+	Date            time.Time `json:"date" gorm:"type:date;uniqueIndex:idx_sprint_day"`
+	RemainingPoints int       `json:"remainingPoints"`
+	CompletedPoints int       `json:"completedPoints"`
+	ScopeAdded      int       `json:"scopeAdded"`
+	ScopeRemoved    int       `json:"scopeRemoved"`
+	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// StringSlice stores a []string as a JSON array column, since the project
+// doesn't depend on a Postgres-specific array driver.
+type StringSlice []string
+
+func (s StringSlice) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
+
+func (s *StringSlice) Scan(value interface{}) error {
+	if value == nil {
+		*s = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		str, ok := value.(string)
+		if !ok {
+			return errors.New("models: StringSlice scan source is not []byte or string")
+		}
+		bytes = []byte(str)
+	}
+
+	if len(bytes) == 0 {
+		*s = nil
+		return nil
+	}
+	return json.Unmarshal(bytes, s)
+}
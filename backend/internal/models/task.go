@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Task struct {
+	ID          uuid.UUID   `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	BoardID     uuid.UUID   `json:"boardId" gorm:"type:uuid;index"`
+	ColumnID    uuid.UUID   `json:"columnId" gorm:"type:uuid;index"`
+	Title       string      `json:"title" binding:"required"`
+	Description string      `json:"description"`
+	AssigneeID  *uuid.UUID  `json:"assigneeId" gorm:"type:uuid"`
+	Assignee    *User       `json:"assignee,omitempty" gorm:"foreignKey:AssigneeID"`
+	SprintID    *uuid.UUID  `json:"sprintId" gorm:"type:uuid;index"`
+	Sprint      *Sprint     `json:"sprint,omitempty" gorm:"foreignKey:SprintID"`
+	Priority    string      `json:"priority"`
+	StoryPoints int         `json:"storyPoints"`
+	Tags        StringSlice `json:"tags" gorm:"type:jsonb"`
+	// Position is a base-62 fractional/lexicographic key (see
+	// internal/ordering) rather than an integer index, so moving a task only
+	// ever updates that one row. The column must use a byte-ordered collation
+	// ("C") for ORDER BY position ASC to sort correctly, since Postgres's
+	// default locale-aware collations don't guarantee ASCII byte order.
+	Position    string     `json:"position" gorm:"type:varchar(64) COLLATE \"C\""`
+	DueDate     *time.Time `json:"dueDate"`
+	CompletedAt *time.Time `json:"completedAt"`
+	CreatedBy   uuid.UUID  `json:"createdBy" gorm:"type:uuid"`
+	UpdatedBy   uuid.UUID  `json:"updatedBy" gorm:"type:uuid"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+
+	// AssigneeEmail is populated by bulk import adapters (see
+	// internal/importers) so the import handler can resolve AssigneeID by
+	// email inside its transaction. It's never persisted or returned from
+	// any API response.
+	AssigneeEmail string `json:"-" gorm:"-"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type User struct {
+	ID           uuid.UUID   `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Name         string      `json:"name"`
+	Email        string      `json:"email" gorm:"uniqueIndex"`
+	Avatar       string      `json:"avatar"`
+	PasswordHash string      `json:"-"`
+	Roles        StringSlice `json:"roles" gorm:"type:jsonb"`
+	// Capacity is how many open tasks/story points of load
+	// executeOverdueReassignment considers this user able to carry before
+	// excluding them from reassignment entirely (see pickLeastLoaded in
+	// internal/api/agent.go).
+	Capacity int `json:"capacity" gorm:"default:8"`
+	// WorkingHours is optional: {"start":"09:00","end":"17:00","timezone":
+	// "America/New_York"}. A user outside their working hours right now is
+	// de-prioritized (not excluded) for reassignment. Nil means "always
+	// available", the same as an empty/unparseable value.
+	WorkingHours JSONMap   `json:"workingHours,omitempty" gorm:"type:jsonb"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,126 @@
+// Package ordering implements fractional/lexicographic position keys for
+// drag-and-drop reordering. Keys are strings over a base-62 alphabet whose
+// byte order already matches digit order ('0'-'9' < 'A'-'Z' < 'a'-'z'), so
+// plain lexicographic comparison (and, given a byte-ordered column
+// collation, a plain SQL ORDER BY) sorts tasks correctly without any
+// integer-length-prefix encoding.
+package ordering
+
+import "errors"
+
+const digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+const base = int64(len(digits))
+
+// DefaultMaxKeyLength is the key length past which NeedsRebalance reports
+// true. Repeatedly inserting at the same spot (e.g. dragging a task back
+// and forth between the same two neighbors) grows the key by one character
+// every five or six inserts, as each insert halves the gap left at the last
+// position; this bounds how long that's allowed to get before
+// the column should be rebalanced.
+const DefaultMaxKeyLength = 20
+
+func digitValue(b byte) int64 {
+	for i := int64(0); i < base; i++ {
+		if digits[i] == b {
+			return i
+		}
+	}
+	return -1
+}
+
+// KeyBetween returns a key that sorts strictly between lower and upper. An
+// empty lower means "no lower bound" (insert at the head); an empty upper
+// means "no upper bound" (insert at the tail); both empty means "first key
+// in an empty column", which returns "a0".
+func KeyBetween(lower, upper string) (string, error) {
+	if lower == "" && upper == "" {
+		return "a0", nil
+	}
+	if upper != "" && lower >= upper {
+		return "", errors.New("ordering: lower must sort before upper")
+	}
+
+	var prefix []byte
+	for i := 0; ; i++ {
+		var loDigit int64
+		if i < len(lower) {
+			loDigit = digitValue(lower[i])
+		} else {
+			loDigit = 0
+		}
+
+		var hiDigit int64
+		hasHi := i < len(upper)
+		if hasHi {
+			hiDigit = digitValue(upper[i])
+		} else {
+			hiDigit = base
+		}
+
+		diff := hiDigit - loDigit
+		if diff >= 2 {
+			mid := loDigit + diff/2
+			return string(append(append([]byte{}, prefix...), digits[mid])), nil
+		}
+
+		// No room between loDigit and hiDigit at this position: carry the
+		// lower digit forward (only when lower actually has a digit here,
+		// otherwise it's an implicit '0') and go one level deeper.
+		if i < len(lower) {
+			prefix = append(prefix, lower[i])
+		} else {
+			prefix = append(prefix, digits[0])
+		}
+
+		// diff == 1 (adjacent digits, e.g. lo='1'/hi='2') means any key we
+		// append from here on sorts before upper regardless of upper's own
+		// remaining digits - the digit we just carried already decides the
+		// comparison in our favor, so upper is truly relinquished. diff == 0
+		// means lower and upper still share this digit, so upper must keep
+		// constraining the next position; relinquishing it here (as the
+		// code previously did unconditionally) is what produced
+		// out-of-range keys like KeyBetween("a0", "a1") == "aV".
+		if diff == 1 {
+			upper = ""
+		}
+	}
+}
+
+// EvenlySpaced returns n strictly increasing, fixed-length keys spread
+// evenly across the keyspace, used by rebalance to reset key growth back
+// to a minimal length.
+func EvenlySpaced(n int) []string {
+	if n <= 0 {
+		return nil
+	}
+
+	length := 1
+	capacity := base
+	for capacity < int64(n)+1 {
+		length++
+		capacity *= base
+	}
+
+	keys := make([]string, n)
+	for i := 0; i < n; i++ {
+		value := int64(i+1) * capacity / int64(n+1)
+		keys[i] = encode(value, length)
+	}
+	return keys
+}
+
+func encode(value int64, length int) string {
+	buf := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		buf[i] = digits[value%base]
+		value /= base
+	}
+	return string(buf)
+}
+
+// NeedsRebalance reports whether key has grown long enough that the column
+// it belongs to should have its positions reissued evenly.
+func NeedsRebalance(key string) bool {
+	return len(key) > DefaultMaxKeyLength
+}
@@ -0,0 +1,91 @@
+package ordering
+
+import "testing"
+
+func TestKeyBetween(t *testing.T) {
+	cases := []struct {
+		name         string
+		lower, upper string
+	}{
+		{"empty column", "", ""},
+		{"insert at head", "", "a0"},
+		{"insert at tail", "a0", ""},
+		{"adjacent single-char keys", "a", "b"},
+		{"shared prefix, adjacent last digit", "a0", "a1"},
+		{"shared prefix, adjacent last digit (letters)", "a1", "a2"},
+		{"shared prefix, adjacent uppercase digit", "aV", "aW"},
+		{"multiple shared prefix levels", "a00", "a01"},
+		{"room between digits", "a", "c"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			key, err := KeyBetween(c.lower, c.upper)
+			if err != nil {
+				t.Fatalf("KeyBetween(%q, %q) returned error: %v", c.lower, c.upper, err)
+			}
+			if c.lower != "" && key <= c.lower {
+				t.Errorf("KeyBetween(%q, %q) = %q, want > lower", c.lower, c.upper, key)
+			}
+			if c.upper != "" && key >= c.upper {
+				t.Errorf("KeyBetween(%q, %q) = %q, want < upper", c.lower, c.upper, key)
+			}
+		})
+	}
+}
+
+func TestKeyBetweenRejectsOutOfOrderBounds(t *testing.T) {
+	if _, err := KeyBetween("b", "a"); err == nil {
+		t.Error("KeyBetween(\"b\", \"a\") should error: lower does not sort before upper")
+	}
+	if _, err := KeyBetween("a", "a"); err == nil {
+		t.Error("KeyBetween(\"a\", \"a\") should error: lower does not sort before upper")
+	}
+}
+
+// TestKeyBetweenRepeatedInsertion simulates dragging a task back and forth
+// between the same two neighbors: each new key must keep sorting strictly
+// between lower and the same upper, and NeedsRebalance must eventually
+// trip so the column gets reissued evenly spaced keys instead of growing
+// without bound.
+func TestKeyBetweenRepeatedInsertion(t *testing.T) {
+	const maxIterations = 200
+
+	lower, upper := "a0", "a1"
+	rebalanced := false
+	for i := 0; i < maxIterations; i++ {
+		key, err := KeyBetween(lower, upper)
+		if err != nil {
+			t.Fatalf("iteration %d: KeyBetween(%q, %q) returned error: %v", i, lower, upper, err)
+		}
+		if key <= lower || key >= upper {
+			t.Fatalf("iteration %d: KeyBetween(%q, %q) = %q, out of range", i, lower, upper, key)
+		}
+		lower = key
+		if NeedsRebalance(lower) {
+			rebalanced = true
+			break
+		}
+	}
+	if !rebalanced {
+		t.Errorf("NeedsRebalance never tripped after %d repeated insertions", maxIterations)
+	}
+}
+
+func TestEvenlySpaced(t *testing.T) {
+	keys := EvenlySpaced(5)
+	if len(keys) != 5 {
+		t.Fatalf("EvenlySpaced(5) returned %d keys, want 5", len(keys))
+	}
+	for i := 1; i < len(keys); i++ {
+		if keys[i-1] >= keys[i] {
+			t.Errorf("EvenlySpaced keys not strictly increasing at index %d: %q >= %q", i, keys[i-1], keys[i])
+		}
+	}
+}
+
+func TestEvenlySpacedEmpty(t *testing.T) {
+	if keys := EvenlySpaced(0); keys != nil {
+		t.Errorf("EvenlySpaced(0) = %v, want nil", keys)
+	}
+}
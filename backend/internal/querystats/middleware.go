@@ -0,0 +1,66 @@
+package querystats
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware stashes a fresh QueryStats on the request context whenever the
+// caller passed ?stats=all, so the querystats.Plugin callbacks and the
+// handler itself (via AddQueryPreparation etc.) can both contribute to it.
+// Handlers use Respond/RespondData to attach the accumulated stats to the
+// response; requests without ?stats=all never allocate a QueryStats.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Query("stats") != "all" {
+			c.Next()
+			return
+		}
+
+		stats := New()
+		c.Request = c.Request.WithContext(NewContext(c.Request.Context(), stats))
+		c.Next()
+	}
+}
+
+// Respond writes response as JSON, merging in a top-level "stats" field
+// when the request asked for it. response must marshal to a JSON object.
+func Respond(c *gin.Context, status int, response interface{}) {
+	stats, ok := FromContext(c.Request.Context())
+	if !ok {
+		c.JSON(status, response)
+		return
+	}
+
+	merged, err := mergeStats(response, stats)
+	if err != nil {
+		c.JSON(status, response)
+		return
+	}
+	c.JSON(status, merged)
+}
+
+// RespondData writes data as a bare JSON array/value, unless the request
+// asked for stats, in which case it's wrapped as {"data": data, "stats": ...}.
+func RespondData(c *gin.Context, status int, data interface{}) {
+	stats, ok := FromContext(c.Request.Context())
+	if !ok {
+		c.JSON(status, data)
+		return
+	}
+	c.JSON(status, gin.H{"data": data, "stats": stats.Snapshot()})
+}
+
+func mergeStats(response interface{}, stats *QueryStats) (map[string]interface{}, error) {
+	body, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+	var merged map[string]interface{}
+	if err := json.Unmarshal(body, &merged); err != nil {
+		return nil, err
+	}
+	merged["stats"] = stats.Snapshot()
+	return merged, nil
+}
@@ -0,0 +1,62 @@
+package querystats
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Plugin is a GORM plugin that times every query/row/raw exec and, when the
+// statement's context carries a *QueryStats (see Middleware), attributes
+// the duration and row count to it. Requests that didn't ask for ?stats=all
+// pay only the cost of a context lookup per query.
+type Plugin struct{}
+
+func (Plugin) Name() string { return "querystats" }
+
+func (Plugin) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Query().Before("gorm:query").Register("querystats:query:before", before); err != nil {
+		return err
+	}
+	if err := db.Callback().Query().After("gorm:query").Register("querystats:query:after", after); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register("querystats:row:before", before); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().After("gorm:row").Register("querystats:row:after", after); err != nil {
+		return err
+	}
+	if err := db.Callback().Raw().Before("gorm:raw").Register("querystats:raw:before", before); err != nil {
+		return err
+	}
+	if err := db.Callback().Raw().After("gorm:raw").Register("querystats:raw:after", after); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const startedAtKey = "querystats:started_at"
+
+func before(db *gorm.DB) {
+	db.InstanceSet(startedAtKey, time.Now())
+}
+
+func after(db *gorm.DB) {
+	raw, ok := db.InstanceGet(startedAtKey)
+	if !ok {
+		return
+	}
+	startedAt, ok := raw.(time.Time)
+	if !ok {
+		return
+	}
+
+	stats, ok := FromContext(db.Statement.Context)
+	if !ok {
+		return
+	}
+
+	stats.AddDBExec(time.Since(startedAt), db.RowsAffected)
+}
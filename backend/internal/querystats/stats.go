@@ -0,0 +1,136 @@
+// Package querystats accumulates per-request database activity (timings,
+// row counts, cache hits) into a context-scoped QueryStats, so analytics
+// handlers can report it back to callers that ask for it via `?stats=all`,
+// the same idea as Prometheus's query API "stats" parameter.
+package querystats
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type ctxKey struct{}
+
+// QueryStats accumulates one request's worth of DB timing and row-count
+// data. Safe for concurrent use since the GORM callbacks in plugin.go run
+// on whichever goroutine issued the query.
+type QueryStats struct {
+	mu sync.Mutex
+
+	started          time.Time
+	queryPreparation time.Duration
+	dbExec           time.Duration
+	postProcessing   time.Duration
+
+	rowsScanned    int64
+	rowsReturned   int64
+	bucketsEmitted int64
+
+	cacheHit          bool
+	cacheTTLRemaining time.Duration
+}
+
+func New() *QueryStats {
+	return &QueryStats{started: time.Now()}
+}
+
+// NewContext returns a copy of ctx carrying stats, for GORM callbacks and
+// handlers to pull out via FromContext.
+func NewContext(ctx context.Context, stats *QueryStats) context.Context {
+	return context.WithValue(ctx, ctxKey{}, stats)
+}
+
+// FromContext retrieves the QueryStats stashed by Middleware, if the
+// request asked for ?stats=all.
+func FromContext(ctx context.Context) (*QueryStats, bool) {
+	stats, ok := ctx.Value(ctxKey{}).(*QueryStats)
+	return stats, ok
+}
+
+func (s *QueryStats) AddQueryPreparation(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queryPreparation += d
+}
+
+func (s *QueryStats) AddDBExec(d time.Duration, rowsScanned int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dbExec += d
+	s.rowsScanned += rowsScanned
+}
+
+func (s *QueryStats) AddPostProcessing(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.postProcessing += d
+}
+
+func (s *QueryStats) AddRowsReturned(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rowsReturned += n
+}
+
+func (s *QueryStats) AddBucketsEmitted(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bucketsEmitted += n
+}
+
+func (s *QueryStats) SetCache(hit bool, ttlRemaining time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cacheHit = hit
+	s.cacheTTLRemaining = ttlRemaining
+}
+
+type Timings struct {
+	QueryPreparationMs int64 `json:"queryPreparation"`
+	DBExecMs           int64 `json:"dbExec"`
+	PostProcessingMs   int64 `json:"postProcessing"`
+	TotalMs            int64 `json:"total"`
+}
+
+type Samples struct {
+	RowsScanned    int64 `json:"rowsScanned"`
+	RowsReturned   int64 `json:"rowsReturned"`
+	BucketsEmitted int64 `json:"bucketsEmitted"`
+}
+
+type Cache struct {
+	Hit            bool  `json:"hit"`
+	TTLRemainingMs int64 `json:"ttlRemaining"`
+}
+
+type Snapshot struct {
+	Timings Timings `json:"timings"`
+	Samples Samples `json:"samples"`
+	Cache   Cache   `json:"cache"`
+}
+
+// Snapshot renders the accumulated stats, closing out "total" as wall time
+// since the request's QueryStats was created.
+func (s *QueryStats) Snapshot() Snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return Snapshot{
+		Timings: Timings{
+			QueryPreparationMs: s.queryPreparation.Milliseconds(),
+			DBExecMs:           s.dbExec.Milliseconds(),
+			PostProcessingMs:   s.postProcessing.Milliseconds(),
+			TotalMs:            time.Since(s.started).Milliseconds(),
+		},
+		Samples: Samples{
+			RowsScanned:    s.rowsScanned,
+			RowsReturned:   s.rowsReturned,
+			BucketsEmitted: s.bucketsEmitted,
+		},
+		Cache: Cache{
+			Hit:            s.cacheHit,
+			TTLRemainingMs: s.cacheTTLRemaining.Milliseconds(),
+		},
+	}
+}
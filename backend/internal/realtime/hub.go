@@ -0,0 +1,191 @@
+package realtime
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+
+	"kanopt/internal/messaging"
+)
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		return origin == "http://localhost:3000" || origin == "http://localhost:3001"
+	},
+}
+
+const heartbeatInterval = 30 * time.Second
+
+// outboxBuffer is how many frames a slow subscriber can fall behind by
+// before Hub drops its connection rather than let it back up fanout for
+// everyone else.
+const outboxBuffer = 64
+
+// Hub serves board-scoped realtime streams. It doesn't consume RabbitMQ
+// itself - live events come from messaging.Broadcaster, and resume replay
+// comes from Recorder.
+type Hub struct {
+	broadcaster *messaging.Broadcaster
+	recorder    *Recorder
+	logger      *logrus.Logger
+}
+
+func NewHub(broadcaster *messaging.Broadcaster, recorder *Recorder, logger *logrus.Logger) *Hub {
+	return &Hub{broadcaster: broadcaster, recorder: recorder, logger: logger}
+}
+
+// frame is the JSON shape written to WebSocket subscribers, pairing each
+// event with the ID a client should echo back as lastEventId to resume.
+type frame struct {
+	ID    string          `json:"id"`
+	Event messaging.Event `json:"event"`
+}
+
+// ServeWebSocket upgrades the request and streams boardID's events to it
+// until the client disconnects or falls too far behind. Resume is driven by
+// a `lastEventId` query param, since the browser WebSocket API can't set a
+// Last-Event-ID header on the upgrade request.
+func (h *Hub) ServeWebSocket(c *gin.Context, boardID uuid.UUID) {
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		h.logger.WithError(err).Warn("realtime: websocket upgrade failed")
+		return
+	}
+	defer conn.Close()
+
+	live, cancel := h.broadcaster.Subscribe(boardID)
+	defer cancel()
+
+	send := make(chan []byte, outboxBuffer)
+	closed := make(chan struct{})
+	go h.writePump(conn, send, closed)
+
+	if lastEventID := c.Query("lastEventId"); lastEventID != "" {
+		backlog, err := h.recorder.Since(c.Request.Context(), boardID.String(), lastEventID)
+		if err != nil {
+			h.logger.WithError(err).WithField("board_id", boardID).Warn("realtime: resume replay failed")
+		}
+		for _, recorded := range backlog {
+			if payload, err := json.Marshal(frame{ID: recorded.ID, Event: recorded.Event}); err == nil {
+				send <- payload
+			}
+		}
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	ctx := c.Request.Context()
+	for {
+		select {
+		case <-closed:
+			return
+		case <-ctx.Done():
+			close(send)
+			return
+		case event, ok := <-live:
+			if !ok {
+				close(send)
+				return
+			}
+			payload, err := json.Marshal(frame{ID: event.ID, Event: event})
+			if err != nil {
+				continue
+			}
+			select {
+			case send <- payload:
+			default:
+				h.logger.WithField("board_id", boardID).Warn("realtime: dropping slow websocket subscriber")
+				close(send)
+				return
+			}
+		case <-heartbeat.C:
+			select {
+			case send <- []byte(`{"type":"heartbeat"}`):
+			default:
+			}
+		}
+	}
+}
+
+// writePump is the only goroutine that writes to conn, since gorilla's
+// websocket.Conn doesn't allow concurrent writers.
+func (h *Hub) writePump(conn *websocket.Conn, send <-chan []byte, closed chan<- struct{}) {
+	defer close(closed)
+	for payload := range send {
+		conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+		if err := conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+			return
+		}
+	}
+	conn.WriteMessage(websocket.CloseMessage, []byte{})
+}
+
+// ServeSSE streams boardID's events as Server-Sent Events. Resume is driven
+// by the standard Last-Event-ID header (sent automatically by EventSource
+// on reconnect) or, as a fallback, a `lastEventId` query param.
+func (h *Hub) ServeSSE(c *gin.Context, boardID uuid.UUID) {
+	live, cancel := h.broadcaster.Subscribe(boardID)
+	defer cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Streaming unsupported"})
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("lastEventId")
+	}
+	if lastEventID != "" {
+		backlog, err := h.recorder.Since(c.Request.Context(), boardID.String(), lastEventID)
+		if err != nil {
+			h.logger.WithError(err).WithField("board_id", boardID).Warn("realtime: resume replay failed")
+		}
+		for _, recorded := range backlog {
+			writeSSEFrame(c.Writer, recorded.ID, recorded.Event)
+		}
+		flusher.Flush()
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	ctx := c.Request.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-live:
+			if !ok {
+				return
+			}
+			writeSSEFrame(c.Writer, event.ID, event)
+			flusher.Flush()
+		case <-heartbeat.C:
+			c.Writer.Write([]byte(": heartbeat\n\n"))
+			flusher.Flush()
+		}
+	}
+}
+
+func writeSSEFrame(w http.ResponseWriter, id string, event messaging.Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	w.Write([]byte("id: " + id + "\nevent: " + event.Type + "\ndata: "))
+	w.Write(payload)
+	w.Write([]byte("\n\n"))
+}
@@ -0,0 +1,94 @@
+package realtime
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"kanopt/internal/messaging"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// TestServeSSEDeliversPublishedEvent publishes a synthetic task.moved event
+// through the Broadcaster and asserts a subscriber connected via ServeSSE
+// receives it on the stream.
+func TestServeSSEDeliversPublishedEvent(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(testWriter{t})
+	broadcaster := messaging.NewStandaloneBroadcaster(logger)
+	hub := NewHub(broadcaster, nil, logger)
+
+	boardID := uuid.New()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/boards/"+boardID.String()+"/events", nil).WithContext(ctx)
+
+	ginCtx, _ := gin.CreateTestContext(w)
+	ginCtx.Request = req
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		hub.ServeSSE(ginCtx, boardID)
+	}()
+
+	// Give ServeSSE time to call Subscribe before we publish, otherwise the
+	// event would be dispatched to no one.
+	time.Sleep(50 * time.Millisecond)
+
+	event := messaging.Event{
+		ID:      "evt-1",
+		Type:    "task.moved",
+		BoardID: boardID.String(),
+		UserID:  uuid.New().String(),
+		Data:    map[string]interface{}{"taskId": "task-1", "toColumnId": "col-2"},
+	}
+	broadcaster.Dispatch(event)
+
+	deadline := time.After(time.Second)
+	for {
+		if strings.Contains(w.Body.String(), "task.moved") {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("subscriber did not receive published event; body so far: %q", w.Body.String())
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	cancel()
+	wg.Wait()
+
+	body := w.Body.String()
+	if !strings.Contains(body, "id: evt-1") {
+		t.Errorf("SSE frame missing id field: %q", body)
+	}
+	if !strings.Contains(body, "event: task.moved") {
+		t.Errorf("SSE frame missing event field: %q", body)
+	}
+	if !strings.Contains(body, `"taskId":"task-1"`) {
+		t.Errorf("SSE frame missing event data: %q", body)
+	}
+}
+
+// testWriter routes logrus output through t.Log so a noisy heartbeat/resume
+// warning doesn't print after the test has finished.
+type testWriter struct{ t *testing.T }
+
+func (w testWriter) Write(p []byte) (int, error) {
+	w.t.Log(strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
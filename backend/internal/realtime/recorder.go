@@ -0,0 +1,94 @@
+// Package realtime backs the board-scoped /api/v1/boards/:id/stream
+// (WebSocket) and /api/v1/boards/:id/events (SSE) endpoints. It reuses
+// messaging.Broadcaster for live fanout rather than consuming RabbitMQ
+// itself, and adds the one thing Broadcaster doesn't provide: a Redis
+// stream of recent events per board so a reconnecting client can resume
+// from a Last-Event-ID even across a server restart, complementing the
+// heavier Postgres-backed replay that events.GetBoardEvents/
+// StreamBoardEvents already offer on the unversioned API.
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/redis/go-redis/v9"
+
+	"kanopt/internal/messaging"
+)
+
+const (
+	streamKeyPrefix = "kanopt:board-events:"
+	// streamMaxLen bounds each board's stream to roughly this many recent
+	// entries (Redis trims approximately, not exactly, via Approx below).
+	streamMaxLen = 1000
+)
+
+// Recorder appends events to a capped Redis stream per board.
+type Recorder struct {
+	client *redis.Client
+}
+
+// NewRecorder connects to redisURL, mirroring cluster.NewLeaderElector's
+// connection setup.
+func NewRecorder(redisURL string) (*Recorder, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{client: redis.NewClient(opts)}, nil
+}
+
+func streamKey(boardID string) string {
+	return streamKeyPrefix + boardID
+}
+
+// Record appends event to its board's stream.
+func (r *Recorder) Record(ctx context.Context, event messaging.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return r.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: streamKey(event.BoardID),
+		MaxLen: streamMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{"event": payload},
+	}).Err()
+}
+
+// RecordedEvent pairs an Event with the Redis stream ID it was recorded
+// under, which becomes the SSE frame's "id:" field and the client's next
+// Last-Event-ID/lastEventId.
+type RecordedEvent struct {
+	ID    string
+	Event messaging.Event
+}
+
+// Since returns every event recorded for boardID strictly after lastID. An
+// empty lastID returns nothing, so a first connect with no resume point
+// gets nothing replayed.
+func (r *Recorder) Since(ctx context.Context, boardID, lastID string) ([]RecordedEvent, error) {
+	if lastID == "" {
+		return nil, nil
+	}
+
+	entries, err := r.client.XRange(ctx, streamKey(boardID), "("+lastID, "+").Result()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]RecordedEvent, 0, len(entries))
+	for _, entry := range entries {
+		raw, ok := entry.Values["event"].(string)
+		if !ok {
+			continue
+		}
+		var event messaging.Event
+		if err := json.Unmarshal([]byte(raw), &event); err != nil {
+			continue
+		}
+		events = append(events, RecordedEvent{ID: entry.ID, Event: event})
+	}
+	return events, nil
+}
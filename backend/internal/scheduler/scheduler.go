@@ -0,0 +1,156 @@
+// Package scheduler runs models.ScheduledAction rows on their cron
+// schedule: at each due NextRunAt it creates a pending models.AgentAction
+// from the schedule's template and dispatches it through
+// api.ExecuteScheduledAction, the same transaction/audit/event path
+// ExecuteAgentAction's HTTP handler uses for one a reviewer triggers by
+// hand. reassign_overdue is the motivating case: an "overdue" job run on a
+// schedule here needs nobody to trigger it.
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"kanopt/internal/api"
+	"kanopt/internal/cluster"
+	"kanopt/internal/cronexpr"
+	"kanopt/internal/messaging"
+	"kanopt/internal/models"
+)
+
+// Scheduler polls models.ScheduledAction for due rows and runs them.
+// leader may be nil, in which case this replica always runs the poll
+// itself (the pre-cluster, single-instance behavior) - the same
+// nil-is-always-leader convention messaging.EventProcessor uses, since
+// running a recurring job on every replica would duplicate its AgentAction
+// every tick.
+type Scheduler struct {
+	db            *gorm.DB
+	rabbitmq      *messaging.RabbitMQ
+	logger        *logrus.Logger
+	leader        *cluster.LeaderElector
+	catchUpWindow time.Duration
+	tracker       *api.ExecutionTracker
+}
+
+// NewScheduler wires up a Scheduler. catchUpWindow bounds how far in the
+// past a missed NextRunAt can be and still fire once when tick() next runs;
+// a row missed by more than that (e.g. the service was down overnight) is
+// rescheduled to its next regular occurrence instead of firing immediately.
+// tracker is forwarded to every ExecuteScheduledAction call so a
+// scheduler-triggered execution is waited on during graceful shutdown the
+// same as one an HTTP request kicked off; it may be nil.
+func NewScheduler(db *gorm.DB, rabbitmq *messaging.RabbitMQ, logger *logrus.Logger, leader *cluster.LeaderElector, catchUpWindow time.Duration, tracker *api.ExecutionTracker) *Scheduler {
+	return &Scheduler{
+		db:            db,
+		rabbitmq:      rabbitmq,
+		logger:        logger,
+		leader:        leader,
+		catchUpWindow: catchUpWindow,
+		tracker:       tracker,
+	}
+}
+
+func (s *Scheduler) isLeader() bool {
+	return s.leader == nil || s.leader.IsLeader()
+}
+
+// Run starts the poll loop in a goroutine, ticking once immediately (so a
+// schedule that came due while the service was down is reconciled at
+// startup rather than waiting a full pollInterval) and every pollInterval
+// after that, until ctx is canceled.
+func (s *Scheduler) Run(ctx context.Context, pollInterval time.Duration) {
+	go func() {
+		s.tick(ctx)
+
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.tick(ctx)
+			}
+		}
+	}()
+}
+
+func (s *Scheduler) tick(ctx context.Context) {
+	if !s.isLeader() {
+		return
+	}
+
+	now := time.Now()
+	var due []models.ScheduledAction
+	if err := s.db.WithContext(ctx).Where("enabled = ? AND next_run_at IS NOT NULL AND next_run_at <= ?", true, now).Find(&due).Error; err != nil {
+		s.logger.WithError(err).Error("scheduler: failed to load due scheduled actions")
+		return
+	}
+
+	for _, scheduled := range due {
+		s.runOne(ctx, scheduled, now)
+	}
+}
+
+// runOne fires scheduled if it's still within catchUpWindow of its
+// NextRunAt, or silently reschedules it (without running) if it's fallen
+// further behind than that - a service down overnight shouldn't replay
+// every missed nightly reassign_overdue run back to back once it's back up.
+func (s *Scheduler) runOne(ctx context.Context, scheduled models.ScheduledAction, now time.Time) {
+	logEntry := s.logger.WithFields(logrus.Fields{
+		"schedule_id": scheduled.ID,
+		"board_id":    scheduled.BoardID,
+		"action_type": scheduled.ActionType,
+	})
+
+	missedBy := now.Sub(*scheduled.NextRunAt)
+	if missedBy > s.catchUpWindow {
+		logEntry.WithField("missed_by", missedBy).Warn("scheduler: missed run outside catch-up window, skipping")
+	} else {
+		if err := s.fire(ctx, scheduled, now); err != nil {
+			logEntry.WithError(err).Error("scheduler: failed to dispatch scheduled agent action")
+		}
+	}
+
+	nextRun, err := cronexpr.NextRun(scheduled.CronExpr, now)
+	if err != nil {
+		logEntry.WithError(err).Error("scheduler: failed to compute next run, disabling schedule")
+		s.db.WithContext(ctx).Model(&models.ScheduledAction{}).Where("id = ?", scheduled.ID).
+			Updates(map[string]interface{}{"enabled": false})
+		return
+	}
+
+	updates := map[string]interface{}{"next_run_at": nextRun}
+	if missedBy <= s.catchUpWindow {
+		updates["last_run_at"] = now
+	}
+	if err := s.db.WithContext(ctx).Model(&models.ScheduledAction{}).Where("id = ?", scheduled.ID).Updates(updates).Error; err != nil {
+		logEntry.WithError(err).Error("scheduler: failed to reschedule next run")
+	}
+}
+
+func (s *Scheduler) fire(ctx context.Context, scheduled models.ScheduledAction, now time.Time) error {
+	action := models.AgentAction{
+		BoardID:     scheduled.BoardID,
+		Type:        scheduled.ActionType,
+		Description: "Scheduled run of " + scheduled.ActionType,
+		Data:        scheduled.Data,
+		Status:      "pending",
+	}
+	if action.Data == nil {
+		action.Data = models.JSONMap{}
+	}
+	if err := s.db.WithContext(ctx).Create(&action).Error; err != nil {
+		return err
+	}
+
+	correlationID := uuid.New().String()
+	actor := "schedule:" + scheduled.ID.String()
+	_, err := api.ExecuteScheduledAction(ctx, s.db, s.rabbitmq, s.logger, s.tracker, action.ID, correlationID, actor)
+	return err
+}
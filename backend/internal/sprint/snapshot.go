@@ -0,0 +1,118 @@
+// Package sprint maintains the daily burndown snapshots that back the
+// analytics burndown chart, independent of the `api` HTTP handlers.
+package sprint
+
+import (
+	"time"
+
+	"kanopt/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Current returns the sprint that contains `at`, preferring one already
+// marked active, and falling back to the most recent sprint whose window
+// contains the given time.
+func Current(db *gorm.DB, boardID uuid.UUID, at time.Time) (*models.Sprint, error) {
+	var sprint models.Sprint
+	err := db.Where("board_id = ? AND status = ?", boardID, models.SprintStatusActive).
+		Order("start_at DESC").
+		First(&sprint).Error
+	if err == nil {
+		return &sprint, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	err = db.Where("board_id = ? AND start_at <= ? AND end_at >= ?", boardID, at, at).
+		Order("start_at DESC").
+		First(&sprint).Error
+	if err != nil {
+		return nil, err
+	}
+	return &sprint, nil
+}
+
+// Snapshot recomputes the SprintDayStat row for the given sprint and day
+// from the current state of its tasks, and upserts it.
+func Snapshot(db *gorm.DB, sprintID uuid.UUID, day time.Time) error {
+	day = day.Truncate(24 * time.Hour)
+
+	var sprintModel models.Sprint
+	if err := db.First(&sprintModel, sprintID).Error; err != nil {
+		return err
+	}
+
+	var tasks []models.Task
+	if err := db.Where("sprint_id = ?", sprintID).Find(&tasks).Error; err != nil {
+		return err
+	}
+
+	remaining, completed := 0, 0
+	scopeAdded, scopeRemoved := 0, 0
+	for _, task := range tasks {
+		if task.CreatedAt.After(sprintModel.StartAt) {
+			scopeAdded += task.StoryPoints
+		}
+		if task.CompletedAt != nil && !task.CompletedAt.After(day) {
+			completed += task.StoryPoints
+		} else {
+			remaining += task.StoryPoints
+		}
+	}
+
+	var previous models.SprintDayStat
+	err := db.Where("sprint_id = ? AND date < ?", sprintID, day).
+		Order("date DESC").
+		First(&previous).Error
+	if err == nil {
+		if removed := previous.RemainingPoints + previous.CompletedPoints - (remaining + completed); removed > 0 {
+			scopeRemoved = removed
+		}
+	} else if err != gorm.ErrRecordNotFound {
+		return err
+	}
+
+	var existing models.SprintDayStat
+	err = db.Where("sprint_id = ? AND date = ?", sprintID, day).First(&existing).Error
+	switch {
+	case err == nil:
+		existing.RemainingPoints = remaining
+		existing.CompletedPoints = completed
+		existing.ScopeAdded = scopeAdded
+		existing.ScopeRemoved = scopeRemoved
+		return db.Save(&existing).Error
+	case err == gorm.ErrRecordNotFound:
+		stat := models.SprintDayStat{
+			SprintID:        sprintID,
+			Date:            day,
+			RemainingPoints: remaining,
+			CompletedPoints: completed,
+			ScopeAdded:      scopeAdded,
+			ScopeRemoved:    scopeRemoved,
+		}
+		return db.Create(&stat).Error
+	default:
+		return err
+	}
+}
+
+// SnapshotActiveSprints recomputes today's SprintDayStat for every active
+// sprint. It's invoked by the daily worker; task writes instead call
+// Snapshot for the one sprint the task belongs to.
+func SnapshotActiveSprints(db *gorm.DB) error {
+	var sprints []models.Sprint
+	if err := db.Where("status = ?", models.SprintStatusActive).Find(&sprints).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, s := range sprints {
+		if err := Snapshot(db, s.ID, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
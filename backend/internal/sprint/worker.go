@@ -0,0 +1,27 @@
+package sprint
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// RunDailyWorker recomputes SprintDayStat rows for every active sprint once
+// a day. It's meant to be launched as a goroutine from main, alongside the
+// event processor.
+func RunDailyWorker(db *gorm.DB, logger *logrus.Logger) {
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+
+	// Run once immediately so a freshly started process has today's stats.
+	if err := SnapshotActiveSprints(db); err != nil {
+		logger.WithError(err).Error("Initial sprint snapshot failed")
+	}
+
+	for range ticker.C {
+		if err := SnapshotActiveSprints(db); err != nil {
+			logger.WithError(err).Error("Daily sprint snapshot failed")
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package websocket
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"kanopt/internal/auth"
+	"kanopt/internal/models"
+)
+
+// Scope is a permission a WebSocket client needs for a specific board
+// action. PermissionChecker.Authorize is consulted against one of these
+// before join_board is accepted, a client's message is broadcast, or a
+// room broadcast is delivered to it.
+type Scope string
+
+const (
+	ScopeJoin    Scope = "join_board"
+	ScopePublish Scope = "publish"
+	ScopeReceive Scope = "receive"
+)
+
+// Session is the result of successfully authenticating a connection: the
+// user it belongs to and the token's ID, which TokenRevoker uses to force
+// the connection closed if the token is later revoked.
+type Session struct {
+	UserID  uuid.UUID
+	TokenID string
+}
+
+// PermissionChecker authorizes a user for a scope on a board. It's an
+// interface so the GORM-backed implementation below can be swapped for one
+// that consults board membership (models.BoardMember) without touching
+// SessionManager or Client.
+type PermissionChecker interface {
+	Authorize(userID, boardID uuid.UUID, scope Scope) (bool, error)
+}
+
+// GormPermissionChecker authorizes against the existing models: a user may
+// act on a board if the board exists. It does not consult
+// models.BoardMember, so every authenticated user is granted every scope on
+// every existing board.
+type GormPermissionChecker struct {
+	db *gorm.DB
+}
+
+func NewGormPermissionChecker(db *gorm.DB) *GormPermissionChecker {
+	return &GormPermissionChecker{db: db}
+}
+
+func (g *GormPermissionChecker) Authorize(userID, boardID uuid.UUID, scope Scope) (bool, error) {
+	var count int64
+	if err := g.db.Model(&models.Board{}).Where("id = ?", boardID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// SessionManager validates the bearer token or signed cookie presented at
+// Upgrade time, resolving it to a Session, and exposes a revocation hook
+// that force-closes any connection still open under a revoked token.
+//
+// Verification is delegated to an *auth.Manager, so a websocket session
+// token is just an access token from the same auth subsystem everything
+// else uses: login mints one JWT and it's valid for both the REST API and
+// the websocket Upgrade handshake.
+type SessionManager struct {
+	tokens  *auth.Manager
+	checker PermissionChecker
+
+	mu       sync.RWMutex
+	revoked  map[string]struct{}
+	onRevoke func(tokenID string)
+}
+
+func NewSessionManager(tokens *auth.Manager, checker PermissionChecker) *SessionManager {
+	return &SessionManager{
+		tokens:  tokens,
+		checker: checker,
+		revoked: make(map[string]struct{}),
+	}
+}
+
+// OnRevoke registers the callback Revoke invokes after marking a token
+// revoked, so the Hub can force-close any connection still open under it.
+func (sm *SessionManager) OnRevoke(fn func(tokenID string)) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.onRevoke = fn
+}
+
+// IssueToken mints a token for userID valid for ttl. It exists so callers
+// without direct access to the auth subsystem (tests, internal tooling)
+// can obtain a token this SessionManager will accept.
+func (sm *SessionManager) IssueToken(userID uuid.UUID, ttl time.Duration) (string, error) {
+	return sm.tokens.IssueAccessToken(userID, nil, ttl)
+}
+
+// Revoke marks tokenID as no longer valid and notifies the callback
+// registered via OnRevoke so affected connections can be force-closed.
+func (sm *SessionManager) Revoke(tokenID string) {
+	sm.mu.Lock()
+	sm.revoked[tokenID] = struct{}{}
+	onRevoke := sm.onRevoke
+	sm.mu.Unlock()
+
+	if onRevoke != nil {
+		onRevoke(tokenID)
+	}
+}
+
+// Authenticate resolves r to a Session, reading the token from the
+// Authorization: Bearer header or, falling back for browsers that can't
+// set custom headers on a WebSocket handshake, a "kanopt_session" cookie.
+func (sm *SessionManager) Authenticate(r *http.Request) (*Session, error) {
+	token := bearerToken(r)
+	if token == "" {
+		if cookie, err := r.Cookie("kanopt_session"); err == nil {
+			token = cookie.Value
+		}
+	}
+	if token == "" {
+		return nil, errors.New("websocket: no session token presented")
+	}
+
+	claims, err := sm.tokens.VerifyAccess(token)
+	if err != nil {
+		return nil, fmt.Errorf("websocket: %w", err)
+	}
+
+	sm.mu.RLock()
+	_, revoked := sm.revoked[claims.ID]
+	sm.mu.RUnlock()
+	if revoked {
+		return nil, errors.New("websocket: session token has been revoked")
+	}
+
+	return &Session{UserID: claims.UserID, TokenID: claims.ID}, nil
+}
+
+// Authorize is a convenience wrapper around the configured
+// PermissionChecker for the given session and board.
+func (sm *SessionManager) Authorize(session *Session, boardID uuid.UUID, scope Scope) (bool, error) {
+	return sm.checker.Authorize(session.UserID, boardID, scope)
+}
+
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if !strings.HasPrefix(header, prefix) {
+		return ""
+	}
+	return strings.TrimPrefix(header, prefix)
+}
@@ -0,0 +1,44 @@
+package websocket
+
+import (
+	"encoding/json"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Codec marshals/unmarshals the Message frames Client exchanges with a
+// browser. Message.Data carries arbitrary JSON-shaped payloads (cursor
+// positions, task drag state, replayed events) that can get large, so a
+// connection can negotiate a denser format without the hub's dispatch logic
+// caring which one it's using.
+type Codec interface {
+	Marshal(Message) ([]byte, error)
+	Unmarshal([]byte, *Message) error
+}
+
+// JSONCodec is the original, default frame format.
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(m Message) ([]byte, error)    { return json.Marshal(m) }
+func (JSONCodec) Unmarshal(b []byte, m *Message) error { return json.Unmarshal(b, m) }
+
+// MsgpackCodec trades JSON's self-describing text format for msgpack's
+// binary one, for clients that negotiate the "msgpack" WebSocket
+// subprotocol.
+type MsgpackCodec struct{}
+
+func (MsgpackCodec) Marshal(m Message) ([]byte, error)    { return msgpack.Marshal(m) }
+func (MsgpackCodec) Unmarshal(b []byte, m *Message) error { return msgpack.Unmarshal(b, m) }
+
+// msgpackSubprotocol is the Sec-WebSocket-Protocol value a browser client
+// offers to request MsgpackCodec instead of the JSON default.
+const msgpackSubprotocol = "msgpack"
+
+// codecForSubprotocol resolves the subprotocol gorilla negotiated during
+// Upgrade to a Codec, defaulting to JSON for "" or anything unrecognized.
+func codecForSubprotocol(subprotocol string) Codec {
+	if subprotocol == msgpackSubprotocol {
+		return MsgpackCodec{}
+	}
+	return JSONCodec{}
+}
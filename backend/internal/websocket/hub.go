@@ -1,13 +1,17 @@
 package websocket
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"github.com/sirupsen/logrus"
+
+	"kanopt/internal/messaging"
 )
 
 var upgrader = websocket.Upgrader{
@@ -16,6 +20,11 @@ var upgrader = websocket.Upgrader{
 		origin := r.Header.Get("Origin")
 		return origin == "http://localhost:3000" || origin == "http://localhost:3001"
 	},
+	// EnableCompression negotiates the permessage-deflate extension with
+	// browser clients that offer it, shrinking frames on the wire without
+	// either side needing to know or care.
+	EnableCompression: true,
+	Subprotocols:      []string{msgpackSubprotocol},
 }
 
 type Hub struct {
@@ -26,14 +35,27 @@ type Hub struct {
 	rooms      map[string]map[*Client]bool
 	mutex      sync.RWMutex
 	logger     *logrus.Logger
+	wal        *messaging.WAL
+	sessions   *SessionManager
 }
 
 type Client struct {
-	hub    *Hub
-	conn   *websocket.Conn
-	send   chan []byte
-	userID string
-	roomID string
+	hub     *Hub
+	conn    *websocket.Conn
+	send    chan []byte
+	session *Session
+	userID  string
+	roomID  string
+
+	// permissions caches the scopes session was granted for roomID at
+	// join_board time, so per-message and per-broadcast re-checks don't
+	// each cost a PermissionChecker round trip. It's rebuilt every time
+	// roomID changes.
+	permissions map[Scope]bool
+
+	// codec is negotiated once at Upgrade time from the client's
+	// Sec-WebSocket-Protocol header and used for every frame thereafter.
+	codec Codec
 }
 
 type Message struct {
@@ -44,20 +66,52 @@ type Message struct {
 	Timestamp time.Time              `json:"timestamp"`
 }
 
-func NewHub(logger *logrus.Logger) *Hub {
-	return &Hub{
+// NewHub creates a Hub. wal may be nil, in which case "resume" messages
+// are accepted but replay nothing. sessions authenticates connections and
+// authorizes board access; it must not be nil.
+func NewHub(logger *logrus.Logger, wal *messaging.WAL, sessions *SessionManager) *Hub {
+	h := &Hub{
 		clients:    make(map[*Client]bool),
 		broadcast:  make(chan []byte),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 		rooms:      make(map[string]map[*Client]bool),
 		logger:     logger,
+		wal:        wal,
+		sessions:   sessions,
+	}
+	sessions.OnRevoke(h.forceCloseToken)
+	return h
+}
+
+// forceCloseToken closes every connection currently authenticated under
+// tokenID. It's registered with the SessionManager as its revocation hook.
+func (h *Hub) forceCloseToken(tokenID string) {
+	h.mutex.RLock()
+	var affected []*Client
+	for client := range h.clients {
+		if client.session != nil && client.session.TokenID == tokenID {
+			affected = append(affected, client)
+		}
+	}
+	h.mutex.RUnlock()
+
+	for _, client := range affected {
+		client.conn.Close()
 	}
 }
 
-func (h *Hub) Run() {
+// Run processes register/unregister/broadcast until ctx is canceled, at
+// which point it tells every connected client the server is going away
+// (see broadcastShutdown) and returns - the caller is then responsible for
+// closing the listener/connections out from under it.
+func (h *Hub) Run(ctx context.Context) {
 	for {
 		select {
+		case <-ctx.Done():
+			h.broadcastShutdown()
+			return
+
 		case client := <-h.register:
 			h.mutex.Lock()
 			h.clients[client] = true
@@ -114,12 +168,42 @@ func (h *Hub) Run() {
 	}
 }
 
-func (h *Hub) BroadcastToRoom(roomID string, message []byte) {
+// JoinRoom moves client from whatever room it's currently in (if any) to
+// roomID, updating the room index under the hub's lock. Pass "" to leave
+// the client's current room without joining a new one.
+func (h *Hub) JoinRoom(client *Client, roomID string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if client.roomID != "" && h.rooms[client.roomID] != nil {
+		delete(h.rooms[client.roomID], client)
+		if len(h.rooms[client.roomID]) == 0 {
+			delete(h.rooms, client.roomID)
+		}
+	}
+
+	client.roomID = roomID
+	if roomID == "" {
+		return
+	}
+	if h.rooms[roomID] == nil {
+		h.rooms[roomID] = make(map[*Client]bool)
+	}
+	h.rooms[roomID][client] = true
+}
+
+// BroadcastToRoom delivers message to every client in roomID whose cached
+// permissions grant requiredScope, so e.g. a cursor_move carrying
+// assignee PII isn't fanned out to a client that only has ScopeJoin.
+func (h *Hub) BroadcastToRoom(roomID string, message []byte, requiredScope Scope) {
 	h.mutex.RLock()
 	defer h.mutex.RUnlock()
-	
+
 	if room, exists := h.rooms[roomID]; exists {
 		for client := range room {
+			if !client.permissions[requiredScope] {
+				continue
+			}
 			select {
 			case client.send <- message:
 			default:
@@ -137,31 +221,103 @@ func (h *Hub) GetConnectionCount() int {
 	return len(h.clients)
 }
 
+// broadcastShutdown tells every connected client the server is going away
+// via a "system.shutdown" message, so a client can proactively reconnect
+// instead of waiting out a dropped-connection timeout once this process
+// exits. Always JSON, same as cursor_move/task_drag: recipients may not
+// share whichever codec any one client negotiated.
+func (h *Hub) broadcastShutdown() {
+	payload, err := json.Marshal(Message{Type: "system.shutdown", Timestamp: time.Now()})
+	if err != nil {
+		return
+	}
+
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	for client := range h.clients {
+		select {
+		case client.send <- payload:
+		default:
+		}
+	}
+}
+
+// HandleWebSocket authenticates r via hub's SessionManager before
+// upgrading, so an unauthenticated or revoked caller gets a plain HTTP
+// rejection instead of a connection it can then try to use. A board
+// passed via the boardId query string is only honored if the resolved
+// session is authorized to join it; otherwise the client connects
+// roomless and must join_board explicitly.
 func HandleWebSocket(hub *Hub, w http.ResponseWriter, r *http.Request, logger *logrus.Logger) {
+	session, err := hub.sessions.Authenticate(r)
+	if err != nil {
+		logger.WithError(err).Warn("Rejected WebSocket connection: authentication failed")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		logger.WithError(err).Error("Failed to upgrade connection")
 		return
 	}
 
-	userID := r.URL.Query().Get("userId")
-	roomID := r.URL.Query().Get("boardId")
-	
 	client := &Client{
-		hub:    hub,
-		conn:   conn,
-		send:   make(chan []byte, 256),
-		userID: userID,
-		roomID: roomID,
+		hub:         hub,
+		conn:        conn,
+		send:        make(chan []byte, 256),
+		session:     session,
+		userID:      session.UserID.String(),
+		permissions: make(map[Scope]bool),
+		codec:       codecForSubprotocol(conn.Subprotocol()),
 	}
 
 	client.hub.register <- client
 
+	if boardIDStr := r.URL.Query().Get("boardId"); boardIDStr != "" {
+		if boardID, err := uuid.Parse(boardIDStr); err == nil {
+			client.tryJoinBoard(boardID)
+		}
+	}
+
 	// Start goroutines for reading and writing
 	go client.writePump()
 	go client.readPump()
 }
 
+// tryJoinBoard authorizes session for boardID's join/publish/receive
+// scopes and, if granted, moves the client into that room and caches the
+// granted scopes on it. A denial leaves the client in its current room
+// (or roomless) rather than erroring the connection.
+func (c *Client) tryJoinBoard(boardID uuid.UUID) bool {
+	granted, err := c.hub.sessions.Authorize(c.session, boardID, ScopeJoin)
+	if err != nil {
+		c.hub.logger.WithError(err).WithField("board_id", boardID).Error("Board join authorization check failed")
+		return false
+	}
+	if !granted {
+		c.hub.logger.WithFields(logrus.Fields{
+			"user_id":  c.session.UserID,
+			"board_id": boardID,
+		}).Warn("Denied join_board: not authorized for this board")
+		return false
+	}
+
+	permissions := map[Scope]bool{ScopeJoin: true}
+	for _, scope := range []Scope{ScopePublish, ScopeReceive} {
+		ok, err := c.hub.sessions.Authorize(c.session, boardID, scope)
+		if err != nil {
+			c.hub.logger.WithError(err).WithField("board_id", boardID).Error("Board scope authorization check failed")
+			continue
+		}
+		permissions[scope] = ok
+	}
+
+	c.hub.JoinRoom(c, boardID.String())
+	c.permissions = permissions
+	return true
+}
+
 func (c *Client) readPump() {
 	defer func() {
 		c.hub.unregister <- c
@@ -185,7 +341,7 @@ func (c *Client) readPump() {
 		}
 
 		var message Message
-		if err := json.Unmarshal(messageBytes, &message); err != nil {
+		if err := c.codec.Unmarshal(messageBytes, &message); err != nil {
 			c.hub.logger.WithError(err).Error("Failed to unmarshal message")
 			continue
 		}
@@ -201,7 +357,7 @@ func (c *Client) readPump() {
 				Type:      "pong",
 				Timestamp: time.Now(),
 			}
-			if pongBytes, err := json.Marshal(pongMessage); err == nil {
+			if pongBytes, err := c.codec.Marshal(pongMessage); err == nil {
 				select {
 				case c.send <- pongBytes:
 				default:
@@ -210,31 +366,87 @@ func (c *Client) readPump() {
 				}
 			}
 		case "join_board":
-			// Client wants to join a specific board room
-			if boardID, ok := message.Data["boardId"].(string); ok {
-				c.roomID = boardID
-				c.hub.register <- c // Re-register with new room
+			// Client wants to join a specific board room; only honored if
+			// its session is authorized for that board.
+			if boardIDStr, ok := message.Data["boardId"].(string); ok {
+				if boardID, err := uuid.Parse(boardIDStr); err == nil {
+					c.tryJoinBoard(boardID)
+				}
 			}
 		case "cursor_move":
-			// Broadcast cursor position to other clients in the same room
-			if c.roomID != "" {
+			// Broadcast cursor position to other clients in the same room.
+			// Fanned-out frames are always JSON regardless of the sender's
+			// negotiated codec, since recipients may not share it.
+			if c.roomID != "" && c.permissions[ScopePublish] {
 				if responseBytes, err := json.Marshal(message); err == nil {
-					c.hub.BroadcastToRoom(c.roomID, responseBytes)
+					c.hub.BroadcastToRoom(c.roomID, responseBytes, ScopeReceive)
 				}
 			}
 		case "task_drag":
-			// Broadcast task dragging state
-			if c.roomID != "" {
+			// Broadcast task dragging state (see cursor_move: JSON only).
+			if c.roomID != "" && c.permissions[ScopePublish] {
 				if responseBytes, err := json.Marshal(message); err == nil {
-					c.hub.BroadcastToRoom(c.roomID, responseBytes)
+					c.hub.BroadcastToRoom(c.roomID, responseBytes, ScopeReceive)
 				}
 			}
+		case "resume":
+			// Client reconnected and wants everything its board's WAL has
+			// recorded since lastSeq, replayed as individual "event" messages.
+			c.handleResume(message)
 		default:
 			c.hub.logger.WithField("message_type", message.Type).Warn("Unknown message type")
 		}
 	}
 }
 
+// handleResume replays a board's WAL from message.Data["lastSeq"] (a JSON
+// number, so it decodes as float64) onto this client alone, each event
+// wrapped as its own "event" Message. It's the WebSocket equivalent of
+// StreamBoardEvents' Last-Event-ID/?since= replay on the SSE side.
+func (c *Client) handleResume(message Message) {
+	if c.hub.wal == nil {
+		return
+	}
+
+	boardID := message.BoardID
+	if boardID == "" {
+		boardID = c.roomID
+	}
+	if boardID == "" {
+		return
+	}
+	if boardID != c.roomID || !c.permissions[ScopeReceive] {
+		c.hub.logger.WithField("board_id", boardID).Warn("Denied resume: not authorized to receive this board's events")
+		return
+	}
+
+	lastSeq, _ := message.Data["lastSeq"].(float64)
+
+	err := c.hub.wal.ReplayFrom(boardID, int64(lastSeq), func(event messaging.Event) error {
+		eventMessage := Message{
+			Type:      "event",
+			BoardID:   event.BoardID,
+			UserID:    event.UserID,
+			Data:      event.Data,
+			Timestamp: event.Timestamp,
+		}
+		payload, err := c.codec.Marshal(eventMessage)
+		if err != nil {
+			return err
+		}
+		select {
+		case c.send <- payload:
+		default:
+			// Client's buffer is full; drop the replay rather than block
+			// the whole hub, same as the broadcast/BroadcastToRoom paths.
+		}
+		return nil
+	})
+	if err != nil {
+		c.hub.logger.WithError(err).WithField("board_id", boardID).Warn("WebSocket resume replay failed")
+	}
+}
+
 func (c *Client) writePump() {
 	ticker := time.NewTicker(54 * time.Second)
 	defer func() {
@@ -3,17 +3,25 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"kanopt/internal/ai"
 	"kanopt/internal/api"
+	"kanopt/internal/audit"
+	"kanopt/internal/auth"
+	"kanopt/internal/cluster"
 	"kanopt/internal/config"
 	"kanopt/internal/database"
+	"kanopt/internal/idempotency"
 	"kanopt/internal/messaging"
+	"kanopt/internal/querystats"
+	"kanopt/internal/realtime"
+	"kanopt/internal/scheduler"
+	"kanopt/internal/sprint"
 	"kanopt/internal/websocket"
 
 	"github.com/gin-contrib/cors"
@@ -23,7 +31,10 @@ import (
 
 func main() {
 	// Load configuration
-	cfg := config.Load()
+	cfg, err := config.Load(context.Background())
+	if err != nil {
+		logrus.New().Fatal("Failed to load configuration:", err)
+	}
 
 	// Setup logger
 	logger := logrus.New()
@@ -36,7 +47,11 @@ func main() {
 	}
 
 	// Initialize database
-	db, err := database.Connect(cfg.DatabaseURL)
+	db, err := database.Connect(cfg.Database.URL, database.PoolConfig{
+		MaxOpenConns:           cfg.Database.MaxOpenConns,
+		MaxIdleConns:           cfg.Database.MaxIdleConns,
+		ConnMaxLifetimeMinutes: cfg.Database.ConnMaxLifetimeMinutes,
+	})
 	if err != nil {
 		logger.Fatal("Failed to connect to database:", err)
 	}
@@ -46,49 +61,220 @@ func main() {
 		logger.Fatal("Failed to run migrations:", err)
 	}
 
+	// WAL durably logs every event (with a per-board sequence number)
+	// before it's published, so a crash between publish and processing
+	// can be reconciled at next boot instead of silently losing the event.
+	wal, err := messaging.NewWAL(cfg.WALDir, time.Duration(cfg.WALMaxAgeHours)*time.Hour, int64(cfg.WALMaxBytesMB)*1024*1024, logger)
+	if err != nil {
+		logger.Fatal("Failed to open event WAL:", err)
+	}
+	defer wal.Close()
+
 	// Initialize RabbitMQ
-	rabbitmq, err := messaging.NewRabbitMQ(cfg.RabbitMQURL, logger)
+	rabbitmq, err := messaging.NewRabbitMQ(cfg.RabbitMQ.URL, logger, wal)
 	if err != nil {
 		logger.Fatal("Failed to connect to RabbitMQ:", err)
 	}
 	defer rabbitmq.Close()
 
-	// Initialize WebSocket hub
-	wsHub := websocket.NewHub(logger)
-	go wsHub.Run()
+	if cfg.EventCodec == "msgpack" {
+		rabbitmq.SetCodec(messaging.MsgpackCodec{})
+	}
+	rabbitmq.SetCompressionThreshold(cfg.EventCompressionThresholdBytes)
+
+	// Broadcaster fans out RabbitMQ events to SSE/WebSocket subscribers
+	broadcaster, err := messaging.NewBroadcaster(rabbitmq, logger)
+	if err != nil {
+		logger.Fatal("Failed to start event broadcaster:", err)
+	}
+
+	// realtimeRecorder backs Last-Event-ID resume for the board-scoped
+	// /api/v1/boards/:id/stream and /:id/events endpoints with a capped
+	// Redis stream per board, independently of broadcaster's live fanout.
+	realtimeRecorder, err := realtime.NewRecorder(cfg.Redis.URL)
+	if err != nil {
+		logger.Fatal("Failed to connect realtime recorder to Redis:", err)
+	}
+	if err := rabbitmq.ConsumeFanout(func(event messaging.Event) {
+		if err := realtimeRecorder.Record(context.Background(), event); err != nil {
+			logger.WithError(err).WithField("board_id", event.BoardID).Warn("realtime: failed to record event")
+		}
+	}); err != nil {
+		logger.Fatal("Failed to start realtime event recorder:", err)
+	}
+	realtimeHub := realtime.NewHub(broadcaster, realtimeRecorder, logger)
+
+	// Dedup rejects redeliveries of events EventProcessor already
+	// finished handling, so a RabbitMQ Nack-with-requeue doesn't
+	// double-count velocity or create duplicate RiskPrediction rows.
+	dedup := messaging.NewDedup(db, time.Duration(cfg.DedupRotateMinutes)*time.Minute)
+
+	// Leader election keeps singleton work (velocity/cycle-time metrics,
+	// bottleneck analysis) running on exactly one replica even though
+	// every replica consumes from the same event queue.
+	leader, err := cluster.NewLeaderElector(cfg.Redis.URL, "kanopt:leader", 15*time.Second, 5*time.Second, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize leader elector:", err)
+	}
+	leader.Run(context.Background())
+	defer leader.Stop()
+
+	// Caches ApproveSuggestion/RejectSuggestion responses by Idempotency-Key
+	// so a client retrying a timed-out request replays the original result
+	// instead of double-creating an agent action or re-publishing its event.
+	idempotencyStore, err := idempotency.NewStore(cfg.Redis.URL, time.Duration(cfg.IdempotencyTTLSeconds)*time.Second)
+	if err != nil {
+		logger.Fatal("Failed to connect idempotency store to Redis:", err)
+	}
+
+	processor := messaging.NewEventProcessor(db, rabbitmq, logger, wal, dedup, leader)
+	processor.MonitorDLQ(cfg.DLQAlertThreshold)
+
+	// shutdownCtx is canceled once SIGINT/SIGTERM is received, telling the
+	// WebSocket hub, event processor, and anything using it for outgoing DB
+	// calls to stop admitting new work; executionTracker then gives whatever
+	// was already in flight up to the shutdown timeout to actually finish.
+	shutdownCtx, cancelShutdown := context.WithCancel(context.Background())
+	executionTracker := api.NewExecutionTracker()
+
+	// Transactional outbox: board/task handlers write their Event into
+	// this table on the same transaction as their mutation, so the two
+	// can never diverge; outboxDispatcher is the background half that
+	// actually publishes those rows to RabbitMQ.
+	outboxWriter := messaging.NewOutboxWriter()
+	outboxDispatcher := messaging.NewOutboxDispatcher(db, rabbitmq, logger)
+	outboxDispatcher.Run(context.Background(), 2*time.Second)
+
+	// Scheduler runs models.ScheduledAction rows (recurring agent actions,
+	// e.g. a nightly reassign_overdue) on their cron schedule; leader-gated
+	// the same way processor is, so a recurring action fires once per tick
+	// across the cluster rather than once per replica.
+	actionScheduler := scheduler.NewScheduler(db, rabbitmq, logger, leader, time.Duration(cfg.SchedulerCatchUpWindowMinutes)*time.Minute, executionTracker)
+	actionScheduler.Run(context.Background(), time.Duration(cfg.SchedulerPollIntervalSeconds)*time.Second)
+
+	// authManager issues/verifies the access and refresh JWTs handlers
+	// behind auth.AuthMiddleware require.
+	authManager, err := auth.NewManager(cfg.Auth)
+	if err != nil {
+		logger.Fatal("Failed to initialize auth manager:", err)
+	}
+
+	// Initialize WebSocket hub. wsSessions authenticates the Upgrade
+	// request and authorizes join_board/publish/receive per board; it
+	// delegates verification to authManager so a token minted at login is
+	// also valid as a websocket session token.
+	wsSessions := websocket.NewSessionManager(authManager, websocket.NewGormPermissionChecker(db))
+	wsHub := websocket.NewHub(logger, wal, wsSessions)
+	go wsHub.Run(shutdownCtx)
+
+	// AI prediction client and its async risk-persistence worker
+	aiClient := ai.NewClient(ai.Config{
+		BaseURL:          cfg.AI.ServiceURL,
+		Timeout:          time.Duration(cfg.AI.TimeoutSeconds) * time.Second,
+		MaxRetries:       cfg.AI.MaxRetries,
+		BreakerThreshold: cfg.AI.BreakerThreshold,
+		BreakerCooldown:  time.Duration(cfg.AI.BreakerCooldownSeconds) * time.Second,
+		CacheTTL:         time.Duration(cfg.AI.CacheTTLSeconds) * time.Second,
+	})
+	riskPersister := ai.NewRiskPersister(db, logger)
+	go riskPersister.Run(context.Background(), 5*time.Second)
+
+	// config.Watch picks up a reloaded config on SIGHUP or a KANOPT_CONFIG
+	// file change; of everything above, only the AI service URL and the DB
+	// pool sizes can be safely re-applied to already-running dependencies
+	// without a restart, so those are the only two wired up here.
+	// RabbitMQ/Redis URL changes still require a restart, since neither
+	// messaging.RabbitMQ nor the realtime/cluster Redis clients support
+	// swapping their connection live.
+	go func() {
+		for reloaded := range config.Watch(context.Background()) {
+			aiClient.SetBaseURL(reloaded.AI.ServiceURL)
+			if err := database.ApplyPoolConfig(db, database.PoolConfig{
+				MaxOpenConns:           reloaded.Database.MaxOpenConns,
+				MaxIdleConns:           reloaded.Database.MaxIdleConns,
+				ConnMaxLifetimeMinutes: reloaded.Database.ConnMaxLifetimeMinutes,
+			}); err != nil {
+				logger.WithError(err).Warn("config: failed to apply reloaded DB pool settings")
+				continue
+			}
+			logger.Info("config: applied reloaded configuration")
+		}
+	}()
 
 	// Setup Gin router
 	router := gin.New()
-	router.Use(gin.Logger())
+	// audit.Middleware assigns the request's correlation ID before
+	// RequestLogger logs it, so every access log line can be joined against
+	// the AuditLog/event trail for the same request.
+	router.Use(audit.Middleware())
+	router.Use(audit.RequestLogger(logger))
 	router.Use(gin.Recovery())
 
 	// CORS configuration
 	corsConfig := cors.DefaultConfig()
 	corsConfig.AllowOrigins = []string{"http://localhost:3000", "http://localhost:3001"}
 	corsConfig.AllowCredentials = true
-	corsConfig.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization"}
+	corsConfig.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization", "Idempotency-Key", "X-Correlation-ID"}
+	corsConfig.ExposeHeaders = []string{"X-Correlation-ID"}
 	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
 	router.Use(cors.New(corsConfig))
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
-			"status": "healthy",
+			"status":    "healthy",
 			"timestamp": time.Now().UTC(),
 			"services": gin.H{
-				"database": "connected",
-				"rabbitmq": "connected",
+				"database":  "connected",
+				"rabbitmq":  "connected",
 				"websocket": "active",
 			},
 			"stats": gin.H{
 				"activeConnections": wsHub.GetConnectionCount(),
-								"activeRooms": 0,
-				"eventQueue":  0,
-				"activeUsers": wsHub.GetConnectionCount(),
+				"activeRooms":       0,
+				"eventQueue":        0,
+				"activeUsers":       wsHub.GetConnectionCount(),
 			},
 		})
 	})
 
+	// Cluster status endpoint, distinct from /health: reports this
+	// replica's role in leader election rather than overall service health.
+	router.GET("/healthz", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"nodeId":   leader.NodeID(),
+			"isLeader": leader.IsLeader(),
+		})
+	})
+
+	// Outbox lag endpoint: how many events are waiting to be published
+	// and, if any are, how long the oldest has been waiting.
+	router.GET("/health/outbox", api.OutboxHealth(outboxDispatcher))
+
+	// Auth routes. Versioned separately from the rest of the API (which
+	// predates this subsystem and isn't versioned) since this is the
+	// surface a frontend pins its token format against.
+	v1 := router.Group("/api/v1")
+	{
+		authRoutes := v1.Group("/auth")
+		{
+			authRoutes.POST("/login", api.Login(db, authManager))
+			authRoutes.POST("/register", api.Register(db, authManager))
+			authRoutes.POST("/refresh", api.Refresh(db, authManager))
+		}
+
+		// Board-scoped realtime streams: a WebSocket at /stream and an SSE
+		// fallback at /events, both fed by the same Broadcaster/Recorder pair.
+		v1Boards := v1.Group("/boards")
+		v1Boards.Use(auth.AuthMiddleware(authManager))
+		{
+			v1Boards.GET("/:id/stream", api.BoardRealtimeStream(db, realtimeHub))
+			v1Boards.GET("/:id/events", api.BoardRealtimeEvents(db, realtimeHub))
+			v1Boards.POST("/:id/import", api.ImportBoard(db, outboxWriter))
+			v1Boards.GET("/:id/export", api.ExportBoard(db))
+		}
+	}
+
 	// API routes
 	apiRoutes := router.Group("/api")
 	{
@@ -96,25 +282,38 @@ func main() {
 		boards := apiRoutes.Group("/boards")
 		{
 			boards.GET("", api.GetBoards(db))
-			boards.POST("", api.CreateBoard(db, rabbitmq))
+			boards.POST("", auth.AuthMiddleware(authManager), api.CreateBoard(db, outboxWriter))
 			boards.GET("/:id", api.GetBoard(db))
-			boards.PUT("/:id", api.UpdateBoard(db, rabbitmq))
-			boards.DELETE("/:id", api.DeleteBoard(db, rabbitmq))
+			boards.PUT("/:id", auth.AuthMiddleware(authManager), api.UpdateBoard(db, outboxWriter))
+			boards.DELETE("/:id", auth.AuthMiddleware(authManager), api.DeleteBoard(db, outboxWriter))
+
+			// Sprints
+			boards.GET("/:id/sprints", api.GetSprints(db))
+			boards.POST("/:id/sprints", api.CreateSprint(db))
+			boards.GET("/:id/sprints/:sprintId", api.GetSprint(db))
+			boards.PUT("/:id/sprints/:sprintId", api.UpdateSprint(db))
+			boards.DELETE("/:id/sprints/:sprintId", api.DeleteSprint(db))
+
+			// Jira import
+			boards.POST("/:id/import/jira", api.StartJiraImport(db, rabbitmq, logger))
+			boards.GET("/:id/import/jira/:jobId", api.GetJiraImportStatus(db))
 		}
 
 		// Task management
 		tasks := apiRoutes.Group("/tasks")
+		tasks.Use(auth.AuthMiddleware(authManager))
 		{
 			tasks.GET("", api.GetTasks(db))
-			tasks.POST("", api.CreateTask(db, rabbitmq))
+			tasks.POST("", api.CreateTask(db, outboxWriter))
 			tasks.GET("/:id", api.GetTask(db))
-			tasks.PUT("/:id", api.UpdateTask(db, rabbitmq))
-			tasks.DELETE("/:id", api.DeleteTask(db, rabbitmq))
-			tasks.POST("/:id/move", api.MoveTask(db, rabbitmq))
+			tasks.PUT("/:id", api.UpdateTask(db, outboxWriter))
+			tasks.DELETE("/:id", api.DeleteTask(db, outboxWriter))
+			tasks.POST("/:id/move", api.MoveTask(db, outboxWriter))
 		}
 
 		// Analytics
 		analytics := apiRoutes.Group("/analytics")
+		analytics.Use(querystats.Middleware())
 		{
 			analytics.GET("/board/:id/velocity", api.GetVelocityMetrics(db))
 			analytics.GET("/board/:id/burndown", api.GetBurndownData(db))
@@ -122,28 +321,56 @@ func main() {
 			analytics.GET("/board/:id/team-performance", api.GetTeamPerformance(db))
 		}
 
-		ai := apiRoutes.Group("/ai")
+		aiRoutes := apiRoutes.Group("/ai")
 		{
-			ai.GET("/board/:id/predictions", api.GetPredictions(db))
-			ai.POST("/board/:id/risk-analysis", api.AnalyzeRisk(db))
+			aiRoutes.GET("/board/:id/predictions", querystats.Middleware(), api.GetPredictions(db, aiClient))
+			aiRoutes.POST("/board/:id/risk-analysis", api.AnalyzeRisk(db, aiClient, riskPersister))
+			aiRoutes.GET("/health", api.AIHealth(aiClient))
+			aiRoutes.GET("/metrics", api.AIMetrics(aiClient))
 		}
 
 		// Agent actions
 		agent := apiRoutes.Group("/agent")
+		agent.Use(auth.AuthMiddleware(authManager))
 		{
 			agent.GET("/suggestions", api.GetSuggestions(db))
-			agent.POST("/suggestions/:id/approve", api.ApproveSuggestion(db, rabbitmq))
-			agent.POST("/suggestions/:id/reject", api.RejectSuggestion(db, rabbitmq))
+			agent.POST("/suggestions/:id/approve", api.ApproveSuggestion(db, rabbitmq, idempotencyStore))
+			agent.POST("/suggestions/:id/reject", api.RejectSuggestion(db, rabbitmq, idempotencyStore))
 			agent.GET("/actions", api.GetAgentActions(db))
-			agent.POST("/actions/:id/execute", api.ExecuteAgentAction(db, rabbitmq))
+			agent.POST("/actions/:id/execute", api.ExecuteAgentAction(db, rabbitmq, logger, executionTracker))
+			agent.POST("/actions/:id/rollback", api.RollbackAgentAction(db, rabbitmq))
+			agent.GET("/policies/:boardId", api.GetAgentPolicy(db))
+			agent.PUT("/policies/:boardId", api.PutAgentPolicy(db))
+
+			schedules := agent.Group("/schedules")
+			{
+				schedules.GET("", api.GetSchedules(db))
+				schedules.POST("", api.CreateSchedule(db))
+				schedules.PUT("/:id", api.UpdateSchedule(db))
+				schedules.DELETE("/:id", api.DeleteSchedule(db))
+			}
 		}
 
 		// Events (event sourcing)
 		events := apiRoutes.Group("/events")
 		{
 			events.GET("/board/:id", api.GetBoardEvents(db))
+			events.GET("/board/:id/stream", api.StreamBoardEvents(db, broadcaster))
+			events.GET("/board/:id/:eventId", api.GetEvent(db))
+			events.POST("/board/:id/archive", api.ArchiveBoardEvents(db))
+			events.GET("/board/:id/replay", api.ReplayBoardEvents(wal))
 			events.POST("", api.CreateEvent(db, rabbitmq))
+
+			// DLQ admin
+			events.GET("/dlq", api.ListDLQEvents(processor))
+			events.POST("/dlq/:eventId/replay", api.ReplayDLQEvent(processor))
+			events.DELETE("/dlq/:eventId", api.DropDLQEvent(processor))
 		}
+
+		// Lets operators trace a suggestion -> approval -> agent action ->
+		// RabbitMQ event -> WebSocket broadcast chain end-to-end by
+		// boardId/correlationId.
+		apiRoutes.GET("/audit", api.GetAuditLog(db))
 	}
 
 	// WebSocket endpoint
@@ -153,31 +380,40 @@ func main() {
 
 	// Create HTTP server
 	server := &http.Server{
-		Addr:    fmt.Sprintf(":%s", cfg.Port),
+		Addr:    fmt.Sprintf(":%s", cfg.HTTP.Port),
 		Handler: router,
 	}
 
 	// Start server in goroutine
 	go func() {
-		logger.Infof("🚀 Starting Kanban API server on port %s", cfg.Port)
+		logger.Infof("🚀 Starting Kanban API server on port %s", cfg.HTTP.Port)
 		logger.Infof("🌐 Environment: %s", cfg.Environment)
-		logger.Infof("📊 Health check: http://localhost:%s/health", cfg.Port)
-		logger.Infof("🔌 WebSocket: ws://localhost:%s/ws", cfg.Port)
-		logger.Infof("📡 API docs: http://localhost:%s/api", cfg.Port)
+		logger.Infof("📊 Health check: http://localhost:%s/health", cfg.HTTP.Port)
+		logger.Infof("🔌 WebSocket: ws://localhost:%s/ws", cfg.HTTP.Port)
+		logger.Infof("📡 API docs: http://localhost:%s/api", cfg.HTTP.Port)
 
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			logger.Fatal("Failed to start server:", err)
 		}
 	}()
 
+	// Reconcile the WAL against the database before consuming any new
+	// events, so events logged but never applied before a prior crash are
+	// re-applied ahead of live traffic.
+	if err := processor.Reconcile(); err != nil {
+		logger.Error("Failed to reconcile event WAL:", err)
+	}
+
 	// Start background event processor
 	go func() {
-		processor := messaging.NewEventProcessor(db, rabbitmq, logger)
-		if err := processor.Start(); err != nil {
+		if err := processor.Start(shutdownCtx); err != nil {
 			logger.Error("Failed to start event processor:", err)
 		}
 	}()
 
+	// Start daily sprint burndown snapshot worker
+	go sprint.RunDailyWorker(db, logger)
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
@@ -188,9 +424,24 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// Stop admitting new work first: wsHub.Run broadcasts a "system.shutdown"
+	// message to every connected client as soon as it observes this, and the
+	// event processor stops picking up anything new. The scheduler keeps
+	// polling, but an agent action it starts once executionTracker.Drain
+	// below has begun is refused. Whatever was already running (an HTTP
+	// request, a scheduled agent action) still gets up to the timeout below
+	// to finish.
+	cancelShutdown()
+
 	if err := server.Shutdown(ctx); err != nil {
-		logger.Fatal("Server forced to shutdown:", err)
+		logger.Error("Server forced to shutdown:", err)
 	}
 
+	// Wait for any agent action execution still mid-transaction - whether
+	// kicked off by the request server.Shutdown just drained or by the
+	// scheduler in its own goroutine - to commit or roll back before the
+	// deferred rabbitmq.Close()/wal.Close() tear down what it needs.
+	executionTracker.Drain(ctx)
+
 	logger.Info("✅ Server shutdown complete")
 }